@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"groupie/models"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// APIArtistsHandler lists artists as cards, one page at a time. It accepts
+// the same filter and sort parameters as the /filter page.
+func APIArtistsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		ErrorHandler(w, r, ErrBadRequest, "Invalid query parameters")
+		return
+	}
+
+	q, err := parseArtistQuery(r.Form)
+	if err != nil {
+		ErrorHandler(w, r, ErrBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, q.paginate(q.artists()))
+}
+
+// APIFiltersHandler describes every registered filter, with the selection
+// given by the same parameters as the /filter page.
+func APIFiltersHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		ErrorHandler(w, r, ErrBadRequest, "Invalid query parameters")
+		return
+	}
+
+	filters, err := ParseFilterSet(r.Form)
+	if err != nil {
+		ErrorHandler(w, r, ErrBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, filters.State())
+}
+
+// APIArtistHandler returns a single artist including its concerts, tours
+// and formatted locations, dates and relations.
+func APIArtistHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		ErrorHandler(w, r, ErrInvalidID, "Invalid artist ID format")
+		return
+	}
+
+	artist, err := dataStore.GetArtist(id)
+	if err != nil {
+		ErrorHandler(w, r, ErrNotFound, "Artist not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, artist)
+}
+
+// APIArtistToursHandler returns an artist's concerts grouped into tours.
+func APIArtistToursHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		ErrorHandler(w, r, ErrInvalidID, "Invalid artist ID format")
+		return
+	}
+
+	artist, err := dataStore.GetArtist(id)
+	if err != nil {
+		ErrorHandler(w, r, ErrNotFound, "Artist not found")
+		return
+	}
+
+	tours := artist.Tours
+	if tours == nil {
+		tours = []models.Tour{}
+	}
+	writeJSON(w, http.StatusOK, tours)
+}
+
+// APILocationsHandler lists every concert location with the artists that
+// played there. An optional q parameter narrows the list by substring.
+func APILocationsHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+
+	artistsByLocation := make(map[string][]int)
+	for _, artist := range dataStore.GetAllArtists() {
+		for _, location := range artist.LocationsList {
+			artistsByLocation[location] = append(artistsByLocation[location], artist.ID)
+		}
+	}
+
+	locations := make([]models.LocationSummary, 0, len(artistsByLocation))
+	for _, location := range dataStore.GetUniqueLocations() {
+		if query != "" && !strings.Contains(strings.ToLower(location), query) {
+			continue
+		}
+		ids := artistsByLocation[location]
+		sort.Ints(ids)
+		locations = append(locations, models.LocationSummary{
+			Location:  location,
+			ArtistIDs: ids,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, locations)
+}
+
+// APISearchHandler returns one page of search results grouped by artist.
+// q may use the field qualifiers understood by ParseSearchQuery; order sets
+// the result type ordering, as in parseTypeOrder.
+func APISearchHandler(w http.ResponseWriter, r *http.Request) {
+	params, err := parseSearchParams(r)
+	if err != nil {
+		ErrorHandler(w, r, ErrBadRequest, err.Error())
+		return
+	}
+
+	page, err := buildSearchPage(params)
+	if err != nil {
+		ErrorHandler(w, r, ErrInvalidQuery, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"groupie/models"
+	"groupie/utils"
+)
+
+// artistQuery is an artist listing request as accepted by /, /filter and
+// /api/v1/artists: the filters, the order and the page to show.
+type artistQuery struct {
+	filters FilterSet
+	order   ArtistSort
+	page    int
+	limit   int
+}
+
+func parseArtistQuery(form url.Values) (artistQuery, error) {
+	var q artistQuery
+	var err error
+	if q.filters, err = ParseFilterSet(form); err != nil {
+		return q, err
+	}
+	if q.order, err = ParseArtistSort(form); err != nil {
+		return q, err
+	}
+
+	q.page = utils.ParseIntDefault(form.Get("page"), 1)
+	q.limit = utils.ParseIntDefault(form.Get("limit"), defaultPageSize)
+	if q.page < 1 || q.limit < 1 || q.limit > maxPageSize {
+		return q, fmt.Errorf("page must be at least 1 and limit between 1 and %d", maxPageSize)
+	}
+	return q, nil
+}
+
+// artists returns every matching artist in order.
+func (q artistQuery) artists() []models.Artist {
+	artists := dataStore.GetAllArtists()
+	if !q.filters.Empty() {
+		artists = NewArtistFilter(q.filters.Predicate()).Filter(artists)
+	}
+	q.order.Apply(artists)
+	return artists
+}
+
+// url links to a page of the same listing under path. The filters and the
+// order are written in their canonical form and the default limit is left
+// out.
+func (q artistQuery) url(path string, page int) string {
+	values := q.filters.Encode()
+	q.order.Encode(values)
+	if q.limit != defaultPageSize {
+		values.Set("limit", strconv.Itoa(q.limit))
+	}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if len(values) == 0 {
+		return path
+	}
+	return path + "?" + values.Encode()
+}
+
+// paginate cuts the requested page out of artists. Next links to the
+// following page of /api/v1/artists, for clients that keep scrolling.
+func (q artistQuery) paginate(artists []models.Artist) models.ArtistPage {
+	start, end := utils.PageBounds(len(artists), q.page, q.limit)
+	page := models.ArtistPage{
+		Artists:    utils.ConvertToCards(artists[start:end]),
+		Sort:       q.order.Value(),
+		Page:       q.page,
+		Limit:      q.limit,
+		Total:      len(artists),
+		TotalPages: utils.TotalPages(len(artists), q.limit),
+	}
+	if q.page < page.TotalPages {
+		page.Next = q.url("/api/v1/artists", q.page+1)
+	}
+	return page
+}
+
+// renderArtistList shows the requested page of artists as the home or filter
+// page, whichever r asked for.
+func renderArtistList(w http.ResponseWriter, r *http.Request, q artistQuery) {
+	artists := q.artists()
+	page := q.paginate(artists)
+	if q.page > 1 && q.page > page.TotalPages {
+		ErrorHandler(w, r, ErrNotFound, fmt.Sprintf("Page %d does not exist; there are %d", q.page, page.TotalPages))
+		return
+	}
+
+	data := models.FilterData{
+		Artists:      page.Artists,
+		FilterState:  q.filters.State(),
+		Sort:         q.order.State(),
+		TotalResults: page.Total,
+		CurrentPath:  r.URL.Path,
+		Page:         page.Page,
+		TotalPages:   page.TotalPages,
+		MoreURL:      page.Next,
+	}
+	if q.limit != defaultPageSize {
+		data.Limit = q.limit
+	}
+	if len(page.Artists) > 0 {
+		data.First = (q.page-1)*q.limit + 1
+		data.Last = data.First + len(page.Artists) - 1
+	}
+	if q.page > 1 {
+		data.PrevURL = q.url(r.URL.Path, q.page-1)
+	}
+	if q.page < page.TotalPages {
+		data.NextURL = q.url(r.URL.Path, q.page+1)
+	}
+
+	if err := executeFilterTemplate(w, data); err != nil {
+		ErrorHandler(w, r, ErrInternalServer, "Failed to process template")
+	}
+}
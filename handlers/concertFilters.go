@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupie/models"
+)
+
+const isoDate = "2006-01-02"
+
+// lastDay is the final day within a parsed date, such as December 31 for a
+// year.
+func lastDay(d models.DateRange) time.Time {
+	return d.To.AddDate(0, 0, -1)
+}
+
+func playedDuring(artist models.Artist, r models.DateRange) bool {
+	for _, concert := range artist.Concerts {
+		if r.Contains(concert.Date) {
+			return true
+		}
+	}
+	return false
+}
+
+// concertWindowFilter keeps artists with at least one concert in dates.
+// from and to are the inclusive bounds as ISO dates, for the date inputs.
+type concertWindowFilter struct {
+	dates    models.DateRange
+	from, to string
+}
+
+func (f concertWindowFilter) Match(artist models.Artist) bool {
+	return playedDuring(artist, f.dates)
+}
+
+func (f concertWindowFilter) Encode(values url.Values) {
+	if f.from != "" {
+		values.Set("concert_from", f.from)
+	}
+	if f.to != "" {
+		values.Set("concert_to", f.to)
+	}
+}
+
+// Both bounds are inclusive and accept anything parseDate does, so
+// concert_to=2019 runs to the end of 2019; they are kept as ISO dates.
+var concertWindowDef = FilterDef{
+	Name:  "concerts",
+	Label: "Concert Dates",
+	Parse: func(form url.Values) (Filter, error) {
+		f := concertWindowFilter{
+			from: strings.TrimSpace(form.Get("concert_from")),
+			to:   strings.TrimSpace(form.Get("concert_to")),
+		}
+		if f.from == "" && f.to == "" {
+			return nil, nil
+		}
+		if f.from != "" {
+			d, ok := parseDate(f.from)
+			if !ok {
+				return nil, fmt.Errorf("concert_from %q is not a date", f.from)
+			}
+			f.dates.From = d.From
+			f.from = d.From.Format(isoDate)
+		}
+		if f.to != "" {
+			d, ok := parseDate(f.to)
+			if !ok {
+				return nil, fmt.Errorf("concert_to %q is not a date", f.to)
+			}
+			f.dates.To = d.To
+			f.to = lastDay(d).Format(isoDate)
+		}
+		if !f.dates.From.IsZero() && !f.dates.To.IsZero() && !f.dates.From.Before(f.dates.To) {
+			return nil, fmt.Errorf("concert_from %s is after concert_to %s", f.from, f.to)
+		}
+		return f, nil
+	},
+	Field: func(active Filter) models.FilterField {
+		f, _ := active.(concertWindowFilter)
+		return models.FilterField{Kind: models.FilterKindInput, Inputs: []models.FilterInput{
+			{Param: "concert_from", Label: "From", Type: "date", Value: f.from},
+			{Param: "concert_to", Label: "To", Type: "date", Value: f.to},
+		}}
+	},
+}
+
+// recentConcertFilter keeps artists that played in the months before now.
+type recentConcertFilter struct {
+	months int
+}
+
+func (f recentConcertFilter) Match(artist models.Artist) bool {
+	now := time.Now()
+	return playedDuring(artist, models.DateRange{From: now.AddDate(0, -f.months, 0), To: now})
+}
+
+func (f recentConcertFilter) Encode(values url.Values) {
+	values.Set("played_within", strconv.Itoa(f.months))
+}
+
+var recentConcertDef = FilterDef{
+	Name:  "recent",
+	Label: "Played Recently",
+	Parse: func(form url.Values) (Filter, error) {
+		value := strings.TrimSpace(form.Get("played_within"))
+		if value == "" {
+			return nil, nil
+		}
+		months, err := strconv.Atoi(value)
+		if err != nil || months < 1 {
+			return nil, fmt.Errorf("played_within must be a number of months, not %q", value)
+		}
+		return recentConcertFilter{months: months}, nil
+	},
+	Field: func(active Filter) models.FilterField {
+		value := ""
+		if f, ok := active.(recentConcertFilter); ok {
+			value = strconv.Itoa(f.months)
+		}
+		return models.FilterField{Kind: models.FilterKindInput, Inputs: []models.FilterInput{
+			{Param: "played_within", Label: "Within the last months", Type: "number", Value: value, Placeholder: "12"},
+		}}
+	},
+}
+
+// showsAfterFilter keeps artists with a concert after the day, month or year
+// given, such as shows after 2019 meaning from 2020 on.
+type showsAfterFilter struct {
+	after time.Time
+	value string
+}
+
+func (f showsAfterFilter) Match(artist models.Artist) bool {
+	return playedDuring(artist, models.DateRange{From: f.after})
+}
+
+func (f showsAfterFilter) Encode(values url.Values) {
+	values.Set("shows_after", f.value)
+}
+
+var showsAfterDef = FilterDef{
+	Name:  "after",
+	Label: "Shows After",
+	Parse: func(form url.Values) (Filter, error) {
+		value := strings.TrimSpace(form.Get("shows_after"))
+		if value == "" {
+			return nil, nil
+		}
+		d, ok := parseDate(value)
+		if !ok {
+			return nil, fmt.Errorf("shows_after %q is not a date", value)
+		}
+		return showsAfterFilter{after: d.To, value: lastDay(d).Format(isoDate)}, nil
+	},
+	Field: func(active Filter) models.FilterField {
+		f, _ := active.(showsAfterFilter)
+		return models.FilterField{Kind: models.FilterKindInput, Inputs: []models.FilterInput{
+			{Param: "shows_after", Label: "Date", Type: "date", Value: f.value},
+		}}
+	},
+}
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
 	"strconv"
 
@@ -12,35 +11,58 @@ import (
 func GetLocationCoordinates(w http.ResponseWriter, r *http.Request) {
 	artistID := r.URL.Query().Get("id")
 	if artistID == "" {
-		ErrorHandler(w, ErrBadRequest, "Artist ID is required")
+		ErrorHandler(w, r, ErrBadRequest, "Artist ID is required")
 		return
 	}
 
 	id, err := strconv.Atoi(artistID)
 	if err != nil {
-		ErrorHandler(w, ErrInvalidID, "Invalid artist ID format")
+		ErrorHandler(w, r, ErrInvalidID, "Invalid artist ID format")
 		return
 	}
 
 	artist, err := dataStore.GetArtist(id)
 	if err != nil {
-		ErrorHandler(w, ErrNotFound, "Artist not found")
+		ErrorHandler(w, r, ErrNotFound, "Artist not found")
 		return
 	}
 
-	var coordinates []models.Coordinates
+	response := models.LocationCoordinates{
+		Complete:  true,
+		Locations: make([]models.LocationStatus, 0, len(artist.LocationsList)),
+		Stops:     make([]models.MapStop, 0, len(artist.Concerts)),
+	}
+
+	dates := make(map[string][]string)
+	for _, concert := range artist.Concerts {
+		dates[concert.Location] = append(dates[concert.Location], concert.Date.Format(isoDate))
+	}
 
+	// Only cached results are returned; missing locations are queued and
+	// reported pending so the client can poll until the map is complete
+	coordinates := make(map[string]*models.Coordinates)
 	for _, location := range artist.LocationsList {
-		// Try to get coordinates from cache/API
-		coords, err := dataStore.GetLocationCoordinates(location)
-		if err != nil {
-			log.Printf("Error getting coordinates for %s: %v", location, err)
-			continue
+		status := dataStore.CoordinateStatus(location)
+		if status.Status == models.StatusPending {
+			response.Complete = false
 		}
-		coordinates = append(coordinates, coords)
+		status.Dates = dates[location]
+		coordinates[location] = status.Coordinates
+		response.Locations = append(response.Locations, status)
+	}
 
+	for i, tour := range artist.Tours {
+		for _, stop := range tour.Stops {
+			response.Stops = append(response.Stops, models.MapStop{
+				Location:    stop.Location,
+				Date:        stop.Date.Format(isoDate),
+				DisplayDate: stop.DisplayDate(),
+				Tour:        i,
+				Coordinates: coordinates[stop.Location],
+			})
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(coordinates)
+	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"groupie/models"
+)
+
+// dateLayouts are tried in order; each parsed date covers a whole day, month
+// or year depending on the layout that matched.
+var dateLayouts = []struct {
+	layout string
+	next   func(time.Time) time.Time
+}{
+	{"2006-01-02", nextDay},
+	{"02-01-2006", nextDay},
+	{"2-1-2006", nextDay},
+	{"02/01/2006", nextDay},
+	{"2/1/2006", nextDay},
+	{"02.01.2006", nextDay},
+	{"January 2, 2006", nextDay},
+	{"January 2 2006", nextDay},
+	{"Jan 2, 2006", nextDay},
+	{"Jan 2 2006", nextDay},
+	{"2 January 2006", nextDay},
+	{"2 Jan 2006", nextDay},
+	{"January 2006", nextMonth},
+	{"Jan 2006", nextMonth},
+	{"2006-01", nextMonth},
+	{"01-2006", nextMonth},
+	{"1-2006", nextMonth},
+	{"01/2006", nextMonth},
+	{"1/2006", nextMonth},
+	{"2006", nextYear},
+}
+
+func nextDay(t time.Time) time.Time   { return t.AddDate(0, 0, 1) }
+func nextMonth(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+func nextYear(t time.Time) time.Time  { return t.AddDate(1, 0, 0) }
+
+// dateFillers are words around a date that carry no meaning for the range,
+// as in "concerts in December 2019".
+var dateFillers = map[string]bool{
+	"concert": true, "concerts": true, "show": true, "shows": true,
+	"gig": true, "gigs": true, "in": true, "on": true, "during": true, "of": true,
+}
+
+// parseDate reads a single year, month or day into the range it covers.
+func parseDate(text string) (models.DateRange, bool) {
+	text = strings.Join(strings.Fields(text), " ")
+	for _, d := range dateLayouts {
+		if t, err := time.Parse(d.layout, text); err == nil {
+			return models.DateRange{From: t, To: d.next(t)}, true
+		}
+	}
+	return models.DateRange{}, false
+}
+
+// parseDateRange understands a single date such as "2019", "December 2019"
+// or "2020-01-15", and ranges written "A..B", "A to B", "from A to B",
+// "between A and B", "A..", "..B", "after A", "since A" and "before B".
+// Surrounding words like "concerts in" are ignored.
+func parseDateRange(text string) (models.DateRange, bool) {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		if !dateFillers[word] {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return models.DateRange{}, false
+	}
+	text = strings.Join(words, " ")
+
+	switch words[0] {
+	case "after":
+		d, ok := parseDate(strings.Join(words[1:], " "))
+		return models.DateRange{From: d.To}, ok
+	case "since":
+		d, ok := parseDate(strings.Join(words[1:], " "))
+		return models.DateRange{From: d.From}, ok
+	case "before":
+		d, ok := parseDate(strings.Join(words[1:], " "))
+		return models.DateRange{To: d.From}, ok
+	case "from", "between":
+		text = strings.Join(words[1:], " ")
+	}
+
+	for _, sep := range []string{"..", " to ", " until ", " and "} {
+		from, to, found := strings.Cut(text, sep)
+		if !found {
+			continue
+		}
+		var r models.DateRange
+		if from = strings.TrimSpace(from); from != "" {
+			d, ok := parseDate(from)
+			if !ok {
+				return models.DateRange{}, false
+			}
+			r.From = d.From
+		}
+		if to = strings.TrimSpace(to); to != "" {
+			d, ok := parseDate(to)
+			if !ok {
+				return models.DateRange{}, false
+			}
+			r.To = d.To
+		}
+		if r.From.IsZero() && r.To.IsZero() || !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+			return models.DateRange{}, false
+		}
+		return r, true
+	}
+	return parseDate(text)
+}
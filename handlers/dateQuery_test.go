@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"groupie/store"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseDateRange(t *testing.T) {
+	tests := []struct {
+		text     string
+		from, to time.Time
+	}{
+		{text: "2019", from: day(2019, 1, 1), to: day(2020, 1, 1)},
+		{text: "December 2019", from: day(2019, 12, 1), to: day(2020, 1, 1)},
+		{text: "concerts in dec 2019", from: day(2019, 12, 1), to: day(2020, 1, 1)},
+		{text: "2019-12", from: day(2019, 12, 1), to: day(2020, 1, 1)},
+		{text: "2020-01-15", from: day(2020, 1, 15), to: day(2020, 1, 16)},
+		{text: "15/01/2020", from: day(2020, 1, 15), to: day(2020, 1, 16)},
+		{text: "2019-11..2020-02", from: day(2019, 11, 1), to: day(2020, 3, 1)},
+		{text: "from 2018 to 2019", from: day(2018, 1, 1), to: day(2020, 1, 1)},
+		{text: "between 2018 and 2019", from: day(2018, 1, 1), to: day(2020, 1, 1)},
+		{text: "2019..", from: day(2019, 1, 1)},
+		{text: "..2019", to: day(2020, 1, 1)},
+		{text: "after 2019", from: day(2020, 1, 1)},
+		{text: "since 2019", from: day(2019, 1, 1)},
+		{text: "before 2019", to: day(2019, 1, 1)},
+	}
+
+	for _, tt := range tests {
+		r, ok := parseDateRange(tt.text)
+		if !ok {
+			t.Errorf("parseDateRange(%q) did not parse", tt.text)
+			continue
+		}
+		if !r.From.Equal(tt.from) || !r.To.Equal(tt.to) {
+			t.Errorf("parseDateRange(%q) = %v..%v, want %v..%v", tt.text, r.From, r.To, tt.from, tt.to)
+		}
+	}
+}
+
+func TestParseDateRangeRejects(t *testing.T) {
+	for _, text := range []string{
+		"queen and metallica",
+		"queen",
+		"concerts",
+		"2020..2019",
+		"2019..queen",
+		"..",
+		"after queen",
+	} {
+		if r, ok := parseDateRange(text); ok {
+			t.Errorf("parseDateRange(%q) = %v..%v, want no date", text, r.From, r.To)
+		}
+	}
+}
+
+// The end of a range is exclusive, so a concert on the first day after it is
+// left out.
+func TestDateRangeEndIsExclusive(t *testing.T) {
+	r, _ := parseDateRange("2019-12-05..2019-12-06")
+	if !r.Contains(day(2019, 12, 6)) {
+		t.Errorf("range %v..%v should contain its last day", r.From, r.To)
+	}
+	if r.Contains(day(2019, 12, 7)) {
+		t.Errorf("range %v..%v should not contain the day after its end", r.From, r.To)
+	}
+}
+
+func useFixtureStore(t *testing.T) {
+	t.Helper()
+	ds := store.New(store.NewFixtureSource())
+	ds.Geocoder = &store.FakeGeocoder{}
+	if err := ds.Initialize(); err != nil {
+		t.Fatalf("load fixture: %v", err)
+	}
+	Initialize(ds)
+}
+
+func TestSearchConcertsPagination(t *testing.T) {
+	useFixtureStore(t)
+
+	// The fixture has five concerts in December 2019, all by Pink Floyd
+	tests := []struct {
+		page      int
+		concerts  int
+		firstDate time.Time
+	}{
+		{page: 1, concerts: 2, firstDate: day(2019, 12, 5)},
+		{page: 2, concerts: 2, firstDate: day(2019, 12, 12)},
+		{page: 3, concerts: 1, firstDate: day(2019, 12, 18)},
+		{page: 4, concerts: 0},
+	}
+
+	for _, tt := range tests {
+		page, err := buildSearchPage(searchParams{
+			query: "concerts in December 2019",
+			page:  tt.page,
+			limit: 2,
+			order: defaultTypeOrder,
+		})
+		if err != nil {
+			t.Fatalf("page %d: %v", tt.page, err)
+		}
+		if page.TotalConcerts != 5 {
+			t.Errorf("page %d: TotalConcerts = %d, want 5", tt.page, page.TotalConcerts)
+		}
+		if page.TotalPages != 3 {
+			t.Errorf("page %d: TotalPages = %d, want 3", tt.page, page.TotalPages)
+		}
+		if len(page.Concerts) != tt.concerts {
+			t.Errorf("page %d: %d concerts, want %d", tt.page, len(page.Concerts), tt.concerts)
+			continue
+		}
+		if tt.concerts > 0 && !page.Concerts[0].Date.Equal(tt.firstDate) {
+			t.Errorf("page %d: first concert on %v, want %v", tt.page, page.Concerts[0].Date, tt.firstDate)
+		}
+	}
+}
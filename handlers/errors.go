@@ -1,48 +1,103 @@
 package handlers
 
 import (
+	"encoding/json"
 	"html/template"
 	"net/http"
+	"strings"
 )
 
 type ErrorType struct {
 	Status  int
+	Code    string
 	Message string
 }
 
 type ErrorData struct {
 	ErrorType
 	Description string
+	RequestID   string
+}
+
+// Problem is the JSON error document returned to API clients.
+type Problem struct {
+	Status    int    `json:"status"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Detail    string `json:"detail,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
 }
 
 var (
 	ErrBadRequest = ErrorType{
 		Status:  http.StatusBadRequest,
+		Code:    "bad_request",
 		Message: "Bad Request",
 	}
 	ErrNotFound = ErrorType{
 		Status:  http.StatusNotFound,
+		Code:    "not_found",
 		Message: "Page Not Found",
 	}
 	ErrInternalServer = ErrorType{
 		Status:  http.StatusInternalServerError,
+		Code:    "internal_error",
 		Message: "Internal Server Error",
 	}
 	ErrInvalidID = ErrorType{
 		Status:  http.StatusBadRequest,
+		Code:    "invalid_id",
 		Message: "Invalid ID Format",
 	}
+	ErrInvalidQuery = ErrorType{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_query",
+		Message: "Invalid Search Query",
+	}
+	ErrMethodNotAllowed = ErrorType{
+		Status:  http.StatusMethodNotAllowed,
+		Code:    "method_not_allowed",
+		Message: "Method Not Allowed",
+	}
 )
 
-// ErrorHandler renders the error page template with provided error information
+// wantsJSON reports whether the error for r should be a JSON problem document:
+// always under /api/, otherwise when the client asks for JSON but not HTML.
+func wantsJSON(r *http.Request) bool {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return (strings.Contains(accept, "application/json") || strings.Contains(accept, "+json")) &&
+		!strings.Contains(accept, "text/html")
+}
+
+// ErrorHandler writes an error response negotiated for the request: a JSON
+// problem document for API clients, the error page template for browsers.
 // If template processing fails, falls back to basic HTTP error response
-func ErrorHandler(w http.ResponseWriter, errType ErrorType, description string) {
+func ErrorHandler(w http.ResponseWriter, r *http.Request, errType ErrorType, description string) {
+	requestID := RequestIDFromContext(r.Context())
+
+	if wantsJSON(r) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(errType.Status)
+		json.NewEncoder(w).Encode(Problem{
+			Status:    errType.Status,
+			Code:      errType.Code,
+			Message:   errType.Message,
+			Detail:    description,
+			RequestID: requestID,
+		})
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(errType.Status)
 
 	data := ErrorData{
 		ErrorType:   errType,
 		Description: description,
+		RequestID:   requestID,
 	}
 
 	tmpl, err := template.ParseFiles("templates/error.html")
@@ -56,3 +111,13 @@ func ErrorHandler(w http.ResponseWriter, errType ErrorType, description string)
 		return
 	}
 }
+
+// MethodNotAllowed answers requests made with any method but the allowed
+// ones, listing them in the Allow header.
+func MethodNotAllowed(allowed ...string) http.HandlerFunc {
+	allow := strings.Join(allowed, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		ErrorHandler(w, r, ErrMethodNotAllowed, r.Method+" is not supported; use "+allow)
+	}
+}
@@ -1,8 +1,13 @@
 package handlers
 
 import (
+	"fmt"
 	"html/template"
+	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 
 	"groupie/models"
@@ -11,147 +16,371 @@ import (
 
 func FilterHandler(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		ErrorHandler(w, ErrBadRequest, "Invalid form data")
+		ErrorHandler(w, r, ErrBadRequest, "Invalid form data")
 		return
 	}
 
-	// Extract filter parameters
-	params := extractFilterParams(r)
-
-	// Check if params match default params
-	defaultParams := utils.GetDefaultFilterParams()
-	if isDefaultParams(params, defaultParams) {
-		// Redirect to home page instead of processing the filter
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+	q, err := parseArtistQuery(r.Form)
+	if err != nil {
+		ErrorHandler(w, r, ErrBadRequest, err.Error())
 		return
 	}
 
-	// Continue with filtering only if params differ from default
-	allArtists := dataStore.GetAllArtists()
-	filteredArtists := NewArtistFilter(params).Filter(allArtists)
-
-	data := models.FilterData{
-		Artists:         utils.ConvertToCards(filteredArtists),
-		UniqueLocations: dataStore.UniqueLocations,
-		SelectedFilters: params,
-		TotalResults:    len(filteredArtists),
-		CurrentPath:     r.URL.Path,
-	}
-
-	if err := executeFilterTemplate(w, data); err != nil {
-		ErrorHandler(w, ErrInternalServer, "Failed to process template")
+	// Nothing to filter on, so show the home page instead, keeping the order
+	// and page
+	if q.filters.Empty() {
+		http.Redirect(w, r, q.url("/", q.page), http.StatusSeeOther)
 		return
 	}
-}
 
-func isDefaultParams(params, defaultParams models.FilterParams) bool {
-	return len(params.MemberCounts) == 0 &&
-		len(params.Locations) == 0 &&
-		params.CreationStart == defaultParams.CreationStart &&
-		params.CreationEnd == defaultParams.CreationEnd &&
-		params.AlbumStartYear == defaultParams.AlbumStartYear &&
-		params.AlbumEndYear == defaultParams.AlbumEndYear
+	renderArtistList(w, r, q)
 }
 
 type ArtistFilter struct {
-	params models.FilterParams
+	predicate Predicate
 }
 
-func NewArtistFilter(params models.FilterParams) *ArtistFilter {
-	return &ArtistFilter{params: params}
+func NewArtistFilter(predicate Predicate) *ArtistFilter {
+	return &ArtistFilter{predicate: predicate}
 }
 
 func (af *ArtistFilter) Filter(artists []models.Artist) []models.Artist {
 	var filtered []models.Artist
 	for _, artist := range artists {
-		if af.matches(artist) {
+		if af.predicate.Match(artist) {
 			filtered = append(filtered, artist)
 		}
 	}
 	return filtered
 }
 
-func (af *ArtistFilter) matches(artist models.Artist) bool {
-	return af.matchesMemberCount(artist) &&
-		af.matchesCreationDate(artist) &&
-		af.matchesLocation(artist) &&
-		af.matchesAlbumYear(artist)
+// maxMemberCount is the last member count option; larger bands count as it.
+const maxMemberCount = 8
+
+type memberCountFilter struct {
+	counts []int
 }
 
-func (af *ArtistFilter) matchesMemberCount(artist models.Artist) bool {
-	if len(af.params.MemberCounts) == 0 {
-		return true
-	}
+func (f memberCountFilter) Match(artist models.Artist) bool {
+	return slices.Contains(f.counts, min(len(artist.Members), maxMemberCount))
+}
 
-	memberCount := len(artist.Members)
-	if memberCount > 8 {
-		memberCount = 8
+func (f memberCountFilter) Encode(values url.Values) {
+	for _, count := range f.counts {
+		values.Add("members", strconv.Itoa(count))
 	}
+}
 
-	for _, count := range af.params.MemberCounts {
-		if memberCount == count {
-			return true
+var memberCountDef = FilterDef{
+	Name:  "members",
+	Label: "Number of Members",
+	Parse: func(form url.Values) (Filter, error) {
+		// Older links send each count as members_N=N
+		values := form["members"]
+		for count := 1; count <= maxMemberCount; count++ {
+			values = append(values, form["members_"+strconv.Itoa(count)]...)
 		}
+
+		var counts []int
+		for _, value := range values {
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 || count > maxMemberCount {
+				return nil, fmt.Errorf("member count must be between 1 and %d, not %q", maxMemberCount, value)
+			}
+			if !slices.Contains(counts, count) {
+				counts = append(counts, count)
+			}
+		}
+		if len(counts) == 0 {
+			return nil, nil
+		}
+		slices.Sort(counts)
+		return memberCountFilter{counts: counts}, nil
+	},
+	Field: func(active Filter) models.FilterField {
+		var selected []int
+		if f, ok := active.(memberCountFilter); ok {
+			selected = f.counts
+		}
+		field := models.FilterField{Kind: models.FilterKindChoice, Param: "members"}
+		for count := 1; count <= maxMemberCount; count++ {
+			field.Options = append(field.Options, models.FilterOption{
+				Value:    strconv.Itoa(count),
+				Label:    strconv.Itoa(count),
+				Selected: slices.Contains(selected, count),
+			})
+		}
+		return field
+	},
+	Option: func(value string) Filter {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil
+		}
+		return memberCountFilter{counts: []int{count}}
+	},
+}
+
+// yearRangeFilter keeps artists whose year, as returned by year, lies within
+// [low, high]. math.MinInt and math.MaxInt leave a side open.
+type yearRangeFilter struct {
+	startParam, endParam string
+	low, high            int
+	year                 func(models.Artist) int
+}
+
+func (f yearRangeFilter) Match(artist models.Artist) bool {
+	year := f.year(artist)
+	return year >= f.low && year <= f.high
+}
+
+func (f yearRangeFilter) Encode(values url.Values) {
+	if f.low != math.MinInt {
+		values.Set(f.startParam, strconv.Itoa(f.low))
 	}
-	return false
+	if f.high != math.MaxInt {
+		values.Set(f.endParam, strconv.Itoa(f.high))
+	}
+}
+
+func creationYear(artist models.Artist) int {
+	return artist.CreationDate
 }
 
-func (af *ArtistFilter) matchesCreationDate(artist models.Artist) bool {
-	return artist.CreationDate >= af.params.CreationStart &&
-		artist.CreationDate <= af.params.CreationEnd
+func albumYear(artist models.Artist) int {
+	return utils.ExtractYear(artist.FirstAlbum)
 }
 
-func (af *ArtistFilter) matchesLocation(artist models.Artist) bool {
-	if len(af.params.Locations) == 0 {
-		return true
+// yearRangeDef defines a slider between minYear and maxYear. A bound left at
+// the end of the slider is open, so artists outside the slider still match.
+// Its options are the decades the slider spans.
+func yearRangeDef(name, label, startParam, endParam string, minYear, maxYear int, year func(models.Artist) int) FilterDef {
+	return FilterDef{
+		Name:  name,
+		Label: label,
+		Parse: func(form url.Values) (Filter, error) {
+			f := yearRangeFilter{startParam: startParam, endParam: endParam, low: math.MinInt, high: math.MaxInt, year: year}
+			for _, bound := range []struct {
+				param string
+				open  func(int) bool
+				dst   *int
+			}{
+				{startParam, func(y int) bool { return y <= minYear }, &f.low},
+				{endParam, func(y int) bool { return y >= maxYear }, &f.high},
+			} {
+				value := form.Get(bound.param)
+				if value == "" {
+					continue
+				}
+				y, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("%s must be a year, not %q", bound.param, value)
+				}
+				if !bound.open(y) {
+					*bound.dst = y
+				}
+			}
+			if f.low == math.MinInt && f.high == math.MaxInt {
+				return nil, nil
+			}
+			if f.low > f.high {
+				return nil, fmt.Errorf("%s %d is after %s %d", startParam, f.low, endParam, f.high)
+			}
+			return f, nil
+		},
+		Field: func(active Filter) models.FilterField {
+			r := &models.FilterRange{StartParam: startParam, EndParam: endParam, Min: minYear, Max: maxYear, Start: minYear, End: maxYear}
+			if f, ok := active.(yearRangeFilter); ok {
+				r.Start = max(f.low, minYear)
+				r.End = min(f.high, maxYear)
+			}
+			field := models.FilterField{Kind: models.FilterKindRange, Range: r}
+			for decade := minYear / 10 * 10; decade <= maxYear; decade += 10 {
+				field.Options = append(field.Options, models.FilterOption{
+					Value:    strconv.Itoa(decade),
+					Label:    fmt.Sprintf("%ds", decade),
+					Selected: r.Start == max(decade, minYear) && r.End == min(decade+9, maxYear),
+				})
+			}
+			return field
+		},
+		Option: func(value string) Filter {
+			decade, err := strconv.Atoi(value)
+			if err != nil {
+				return nil
+			}
+			return yearRangeFilter{startParam: startParam, endParam: endParam, low: decade, high: decade + 9, year: year}
+		},
 	}
+}
 
-	for _, filterLocation := range af.params.Locations {
-		filterLocationLower := strings.ToLower(filterLocation)
+// locationFilter matches artists that played any of the locations. A
+// location may also be a region, country or continent, or part of a location
+// name typed freely, as in location=london.
+type locationFilter struct {
+	locations []string
+}
 
-		for _, artistLocation := range artist.LocationsList {
-			if strings.Contains(strings.ToLower(artistLocation), filterLocationLower) {
+func (f locationFilter) Match(artist models.Artist) bool {
+	for _, artistLocation := range artist.LocationsList {
+		place, _ := utils.ResolvePlace(artistLocation)
+		lower := strings.ToLower(artistLocation)
+		for _, location := range f.locations {
+			if place.In(location) || strings.Contains(lower, strings.ToLower(location)) {
 				return true
 			}
 		}
+	}
+	return false
+}
 
-		if citiesInState, exists := artist.LocationStatesCities[filterLocation]; exists && len(citiesInState) > 0 {
-			return true
-		}
+func (f locationFilter) Encode(values url.Values) {
+	for _, location := range f.locations {
+		values.Add("location", location)
 	}
+}
 
-	return false
+var locationDef = FilterDef{
+	Name:  "location",
+	Label: "Concert Locations",
+	Parse: func(form url.Values) (Filter, error) {
+		var locations []string
+		for _, location := range form["location"] {
+			if location = strings.TrimSpace(location); location != "" && !slices.Contains(locations, location) {
+				locations = append(locations, location)
+			}
+		}
+		if len(locations) == 0 {
+			return nil, nil
+		}
+		return locationFilter{locations: locations}, nil
+	},
+	Field: func(active Filter) models.FilterField {
+		var selected []string
+		if f, ok := active.(locationFilter); ok {
+			selected = f.locations
+		}
+		field := models.FilterField{Kind: models.FilterKindChoice, Param: "location"}
+		for _, location := range dataStore.GetUniqueLocations() {
+			field.Options = append(field.Options, models.FilterOption{
+				Value:    location,
+				Label:    location,
+				Selected: slices.Contains(selected, location),
+			})
+		}
+		return field
+	},
+	Option: func(value string) Filter {
+		return locationFilter{locations: []string{value}}
+	},
 }
 
-func (af *ArtistFilter) matchesAlbumYear(artist models.Artist) bool {
-	albumYear := utils.ExtractYear(artist.FirstAlbum)
-	return albumYear >= af.params.AlbumStartYear &&
-		albumYear <= af.params.AlbumEndYear
+type regionFilter struct {
+	regions []string
 }
 
-func extractFilterParams(r *http.Request) models.FilterParams {
-	return models.FilterParams{
-		MemberCounts:   utils.GetMemberCounts(r),
-		Locations:      r.Form["location"],
-		CreationStart:  utils.ParseIntDefault(r.FormValue("creation_start"), 1950),
-		CreationEnd:    utils.ParseIntDefault(r.FormValue("creation_end"), 2024),
-		AlbumStartYear: utils.ParseIntDefault(r.FormValue("album_start"), 1950),
-		AlbumEndYear:   utils.ParseIntDefault(r.FormValue("album_end"), 2024),
+// Match compares whole hierarchy names only; unlike locationFilter there is
+// no substring fallback, so "Uk" does not match "Kyiv, Ukraine".
+func (f regionFilter) Match(artist models.Artist) bool {
+	for _, location := range artist.LocationsList {
+		place, _ := utils.ResolvePlace(location)
+		for _, region := range f.regions {
+			if place.In(region) {
+				return true
+			}
+		}
 	}
+	return false
 }
 
-func executeFilterTemplate(w http.ResponseWriter, data models.FilterData) error {
-	funcMap := template.FuncMap{
-		"iterate": func(start, end int) []int {
-			var result []int
-			for i := start; i <= end; i++ {
-				result = append(result, i)
+func (f regionFilter) Encode(values url.Values) {
+	for _, region := range f.regions {
+		values.Add("region", region)
+	}
+}
+
+// regionDef offers every continent, country and region that has concerts,
+// nested in that order.
+var regionDef = FilterDef{
+	Name:  "region",
+	Label: "Regions",
+	Parse: func(form url.Values) (Filter, error) {
+		var regions []string
+		for _, region := range form["region"] {
+			if region = strings.TrimSpace(region); region != "" && !slices.Contains(regions, region) {
+				regions = append(regions, region)
 			}
-			return result
-		},
+		}
+		if len(regions) == 0 {
+			return nil, nil
+		}
+		return regionFilter{regions: regions}, nil
+	},
+	Field: func(active Filter) models.FilterField {
+		var selected []string
+		if f, ok := active.(regionFilter); ok {
+			selected = f.regions
+		}
+
+		tree := make(map[string]map[string]map[string]bool)
+		for _, location := range dataStore.GetUniqueLocations() {
+			place, _ := utils.ResolvePlace(location)
+			if place.Country == "" {
+				continue
+			}
+			if tree[place.Continent] == nil {
+				tree[place.Continent] = make(map[string]map[string]bool)
+			}
+			if tree[place.Continent][place.Country] == nil {
+				tree[place.Continent][place.Country] = make(map[string]bool)
+			}
+			if place.State != "" {
+				tree[place.Continent][place.Country][place.Region()] = true
+			}
+		}
+
+		field := models.FilterField{Kind: models.FilterKindChoice, Param: "region"}
+		add := func(value, label string, level int) {
+			field.Options = append(field.Options, models.FilterOption{
+				Value:    value,
+				Label:    label,
+				Level:    level,
+				Selected: slices.Contains(selected, value),
+			})
+		}
+		// Countries missing from the gazetteer have no continent and are
+		// listed at the top level
+		for _, continent := range sortedKeys(tree) {
+			level := 0
+			if continent != "" {
+				add(continent, continent, 0)
+				level = 1
+			}
+			for _, country := range sortedKeys(tree[continent]) {
+				add(country, country, level)
+				for _, region := range sortedKeys(tree[continent][country]) {
+					add(region, strings.TrimSuffix(region, ", "+country), level+1)
+				}
+			}
+		}
+		return field
+	},
+	Option: func(value string) Filter {
+		return regionFilter{regions: []string{value}}
+	},
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
+	slices.Sort(keys)
+	return keys
+}
 
-	tmpl, err := template.New("index.html").Funcs(funcMap).ParseFiles("templates/index.html")
+func executeFilterTemplate(w http.ResponseWriter, data models.FilterData) error {
+	tmpl, err := template.ParseFiles("templates/index.html")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+
+	"groupie/models"
+)
+
+// Predicate reports whether an artist passes a filter.
+type Predicate interface {
+	Match(artist models.Artist) bool
+}
+
+// And matches artists that pass every predicate; an empty And matches all.
+type And []Predicate
+
+func (a And) Match(artist models.Artist) bool {
+	for _, p := range a {
+		if !p.Match(artist) {
+			return false
+		}
+	}
+	return true
+}
+
+// Or matches artists that pass at least one predicate.
+type Or []Predicate
+
+func (o Or) Match(artist models.Artist) bool {
+	for _, p := range o {
+		if p.Match(artist) {
+			return true
+		}
+	}
+	return false
+}
+
+type Not struct {
+	Predicate
+}
+
+func (n Not) Match(artist models.Artist) bool {
+	return !n.Predicate.Match(artist)
+}
+
+// Filter is a parsed, active filter that can write itself back as form values.
+type Filter interface {
+	Predicate
+	Encode(values url.Values)
+}
+
+// FilterDef is a named filter in the registry. Parse reads it from form
+// values and returns nil when the form leaves it unset. Field describes it
+// for the sidebar and the JSON API, given the active filter or nil. Option,
+// when set, builds the filter selecting a single option of the field, which
+// is used to count artists per option.
+type FilterDef struct {
+	Name   string
+	Label  string
+	Parse  func(form url.Values) (Filter, error)
+	Field  func(active Filter) models.FilterField
+	Option func(value string) Filter
+}
+
+// filterRegistry lists every filter in the order the sidebar shows them.
+var filterRegistry = []FilterDef{
+	memberCountDef,
+	yearRangeDef("creation", "Creation Date", "creation_start", "creation_end", 1950, 2024, creationYear),
+	yearRangeDef("album", "First Album Year", "album_start", "album_end", 1950, 2024, albumYear),
+	regionDef,
+	locationDef,
+	concertWindowDef,
+	recentConcertDef,
+	showsAfterDef,
+}
+
+// FilterSet holds the active filters of a request. Filters are ANDed unless
+// MatchAny is set, and each one may be negated. Form values use "match=any"
+// and one "not=<name>" per negated filter.
+type FilterSet struct {
+	Filters  map[string]Filter
+	Negated  map[string]bool
+	MatchAny bool
+}
+
+func ParseFilterSet(form url.Values) (FilterSet, error) {
+	fs := FilterSet{
+		Filters: make(map[string]Filter),
+		Negated: make(map[string]bool),
+	}
+
+	for _, def := range filterRegistry {
+		filter, err := def.Parse(form)
+		if err != nil {
+			return FilterSet{}, fmt.Errorf("%s: %w", def.Label, err)
+		}
+		if filter != nil {
+			fs.Filters[def.Name] = filter
+		}
+	}
+
+	for _, name := range form["not"] {
+		if !isRegisteredFilter(name) {
+			return FilterSet{}, fmt.Errorf("cannot negate unknown filter %q", name)
+		}
+		if fs.Filters[name] != nil {
+			fs.Negated[name] = true
+		}
+	}
+
+	switch form.Get("match") {
+	case "", "all":
+	case "any":
+		fs.MatchAny = true
+	default:
+		return FilterSet{}, fmt.Errorf("match must be all or any, not %q", form.Get("match"))
+	}
+	return fs, nil
+}
+
+func isRegisteredFilter(name string) bool {
+	for _, def := range filterRegistry {
+		if def.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (fs FilterSet) Empty() bool {
+	return len(fs.Filters) == 0
+}
+
+// Predicate combines the active filters. With no active filters every
+// artist matches, whatever MatchAny says.
+func (fs FilterSet) Predicate() Predicate {
+	var parts []Predicate
+	for _, def := range filterRegistry {
+		filter := fs.Filters[def.Name]
+		if filter == nil {
+			continue
+		}
+		if fs.Negated[def.Name] {
+			parts = append(parts, Not{filter})
+		} else {
+			parts = append(parts, filter)
+		}
+	}
+
+	if fs.MatchAny && len(parts) > 0 {
+		return Or(parts)
+	}
+	return And(parts)
+}
+
+// Encode returns the form values that parse back into fs.
+func (fs FilterSet) Encode() url.Values {
+	values := url.Values{}
+	for _, def := range filterRegistry {
+		filter := fs.Filters[def.Name]
+		if filter == nil {
+			continue
+		}
+		filter.Encode(values)
+		if fs.Negated[def.Name] {
+			values.Add("not", def.Name)
+		}
+	}
+	if fs.MatchAny {
+		values.Set("match", "any")
+	}
+	return values
+}
+
+// without returns a copy of fs with the named filter removed.
+func (fs FilterSet) without(name string) FilterSet {
+	other := FilterSet{
+		Filters:  make(map[string]Filter, len(fs.Filters)),
+		Negated:  make(map[string]bool, len(fs.Negated)),
+		MatchAny: fs.MatchAny,
+	}
+	for n, filter := range fs.Filters {
+		if n != name {
+			other.Filters[n] = filter
+			other.Negated[n] = fs.Negated[n]
+		}
+	}
+	return other
+}
+
+// countOptions sets the facet count of every option of field: the artists
+// that would match if the option were the field's only selection, combined
+// with the other active filters as fs combines them. With match=any an option
+// adds to the artists the others match rather than narrowing them. When the
+// field is negated the option is counted negated too.
+func (fs FilterSet) countOptions(def FilterDef, field *models.FilterField, artists []models.Artist) {
+	if def.Option == nil || len(field.Options) == 0 {
+		return
+	}
+
+	rest := fs.without(def.Name)
+	others := rest.Predicate()
+	for i := range field.Options {
+		option := &field.Options[i]
+		filter := def.Option(option.Value)
+		if filter == nil {
+			continue
+		}
+		var predicate Predicate = filter
+		if fs.Negated[def.Name] {
+			predicate = Not{filter}
+		}
+		for _, artist := range artists {
+			var match bool
+			if fs.MatchAny && !rest.Empty() {
+				match = others.Match(artist) || predicate.Match(artist)
+			} else {
+				match = others.Match(artist) && predicate.Match(artist)
+			}
+			if match {
+				option.Count++
+			}
+		}
+		option.Disabled = option.Count == 0 && !option.Selected
+	}
+}
+
+// State describes every registered filter with the selection in fs and the
+// facet count of each option.
+func (fs FilterSet) State() models.FilterState {
+	state := models.FilterState{
+		Filters: make([]models.FilterField, 0, len(filterRegistry)),
+		Match:   "all",
+		Query:   fs.Encode().Encode(),
+	}
+	if fs.MatchAny {
+		state.Match = "any"
+	}
+
+	artists := dataStore.GetAllArtists()
+	for _, def := range filterRegistry {
+		filter := fs.Filters[def.Name]
+		field := def.Field(filter)
+		field.Name = def.Name
+		field.Label = def.Label
+		field.Active = filter != nil
+		field.Negated = fs.Negated[def.Name]
+		fs.countOptions(def, &field, artists)
+		state.Filters = append(state.Filters, field)
+	}
+	return state
+}
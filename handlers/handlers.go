@@ -5,9 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
-	"groupie/models"
 	"groupie/store"
-	"groupie/utils"
 )
 
 var dataStore *store.DataStore
@@ -18,53 +16,54 @@ func Initialize(ds *store.DataStore) {
 
 func HomeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
-		ErrorHandler(w, ErrNotFound, "Page not exist")
+		ErrorHandler(w, r, ErrNotFound, "Page not exist")
 		return
 	}
 
-	data := models.FilterData{
-		Artists:         dataStore.GetArtistCards(),
-		UniqueLocations: dataStore.UniqueLocations,
-		SelectedFilters: utils.GetDefaultFilterParams(),
-		TotalResults:    len(dataStore.GetArtistCards()),
-		CurrentPath:     r.URL.Path,
+	q, err := parseArtistQuery(r.URL.Query())
+	if err != nil {
+		ErrorHandler(w, r, ErrBadRequest, err.Error())
+		return
 	}
 
-	if err := executeFilterTemplate(w, data); err != nil {
-		ErrorHandler(w, ErrInternalServer, "Failed to process template")
+	// Filtered listings live at /filter
+	if !q.filters.Empty() {
+		http.Redirect(w, r, q.url("/filter", q.page), http.StatusSeeOther)
 		return
 	}
+
+	renderArtistList(w, r, q)
 }
 
 func ArtistHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := r.URL.Query().Get("id")
 	if idStr == "" {
-		ErrorHandler(w, ErrBadRequest, "Artist ID is required")
+		ErrorHandler(w, r, ErrBadRequest, "Artist ID is required")
 		return
 	}
 
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		ErrorHandler(w, ErrInvalidID, "Invalid artist ID format")
+		ErrorHandler(w, r, ErrInvalidID, "Invalid artist ID format")
 		return
 	}
 
 	artist, err := dataStore.GetArtist(id)
 	if err != nil {
-		ErrorHandler(w, ErrNotFound, "Artist not found")
+		ErrorHandler(w, r, ErrNotFound, "Artist not found")
 		return
 	}
 
 	tmpl, err := template.ParseFiles("templates/artist.html")
 	if err != nil {
-		ErrorHandler(w, ErrInternalServer, "Failed to load template")
+		ErrorHandler(w, r, ErrInternalServer, "Failed to load template")
 		return
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	err = tmpl.Execute(w, artist)
 	if err != nil {
-		ErrorHandler(w, ErrInternalServer, "Failed to execute template")
+		ErrorHandler(w, r, ErrInternalServer, "Failed to execute template")
 		return
 	}
 }
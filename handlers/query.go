@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"groupie/models"
+	"groupie/store"
+)
+
+// Search query qualifiers. Text qualifiers restrict matching to one field of
+// the search index; range qualifiers become ArtistFilter predicates.
+var (
+	textQualifiers = map[string]string{
+		"artist":   store.TypeArtist,
+		"name":     store.TypeArtist,
+		"member":   store.TypeMember,
+		"location": store.TypeLocation,
+	}
+	rangeQualifiers = map[string]bool{
+		"formed":  true,
+		"album":   true,
+		"members": true,
+	}
+)
+
+// QueryError describes a syntax error in a search query.
+type QueryError struct {
+	Token   string
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	if e.Token == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%q: %s", e.Token, e.Message)
+}
+
+type fieldTerm struct {
+	Field string
+	Value string
+}
+
+// SearchQuery is a parsed query such as
+// `member:freddie location:london formed:1970..1975 album:<1980 queen`.
+// Range and date qualifiers become Filters; Dates is also kept so the
+// matching concerts can be listed.
+type SearchQuery struct {
+	Text    string
+	Terms   []fieldTerm
+	Filters And
+	Dates   *models.DateRange
+}
+
+// isStructured reports whether query uses any qualifier and so needs parsing.
+func isStructured(query string) bool {
+	for _, token := range splitQuery(query) {
+		if key, _, ok := strings.Cut(token, ":"); ok && isQualifier(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isQualifier reports whether key names a known qualifier. Other "word:"
+// tokens, such as "http://" or "note:", are free text.
+func isQualifier(key string) bool {
+	key = strings.ToLower(key)
+	return textQualifiers[key] != "" || rangeQualifiers[key] || key == "date"
+}
+
+// splitQuery splits on whitespace, keeping double-quoted sections together
+// and dropping the quotes.
+func splitQuery(query string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// ParseSearchQuery parses free text mixed with field qualifiers.
+func ParseSearchQuery(query string) (SearchQuery, error) {
+	if strings.Count(query, `"`)%2 != 0 {
+		return SearchQuery{}, &QueryError{Message: "unterminated quote"}
+	}
+
+	var sq SearchQuery
+	var text []string
+
+	for _, token := range splitQuery(query) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || !isQualifier(key) {
+			text = append(text, token)
+			continue
+		}
+
+		key = strings.ToLower(key)
+		if value == "" {
+			return SearchQuery{}, &QueryError{Token: token, Message: fmt.Sprintf("qualifier %s: needs a value", key)}
+		}
+
+		if field, ok := textQualifiers[key]; ok {
+			sq.Terms = append(sq.Terms, fieldTerm{Field: field, Value: value})
+			continue
+		}
+		if key == "date" {
+			dates, ok := parseDateRange(value)
+			if !ok {
+				return SearchQuery{}, &QueryError{Token: token, Message: "not a date or date range; try 2019, 2019-12, \"December 2019\", 2020-01-15 or 2019-11..2020-02"}
+			}
+			sq.Dates = &dates
+			sq.Filters = append(sq.Filters, concertWindowFilter{dates: dates})
+			continue
+		}
+		low, high, err := parseRange(value)
+		if err != nil {
+			return SearchQuery{}, &QueryError{Token: token, Message: err.Error()}
+		}
+		switch key {
+		case "formed":
+			sq.Filters = append(sq.Filters, yearRangeFilter{low: low, high: high, year: creationYear})
+		case "album":
+			sq.Filters = append(sq.Filters, yearRangeFilter{low: low, high: high, year: albumYear})
+		case "members":
+			counts := memberCountsBetween(low, high)
+			if len(counts) == 0 {
+				return SearchQuery{}, &QueryError{Token: token, Message: fmt.Sprintf("member count must be between 1 and %d", maxMemberCount)}
+			}
+			sq.Filters = append(sq.Filters, memberCountFilter{counts: counts})
+		}
+	}
+
+	sq.Text = strings.Join(text, " ")
+	return sq, nil
+}
+
+// parseRange accepts N, N..M, N.., ..M, <N, <=N, >N and >=N and returns the
+// inclusive bounds.
+func parseRange(value string) (low, high int, err error) {
+	low, high = 0, 9999
+	number := func(s string) (int, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+		return n, nil
+	}
+
+	switch {
+	case strings.Contains(value, ".."):
+		from, to, _ := strings.Cut(value, "..")
+		if from == "" && to == "" {
+			return 0, 0, fmt.Errorf("range needs at least one bound")
+		}
+		if from != "" {
+			if low, err = number(from); err != nil {
+				return 0, 0, err
+			}
+		}
+		if to != "" {
+			if high, err = number(to); err != nil {
+				return 0, 0, err
+			}
+		}
+		if low > high {
+			return 0, 0, fmt.Errorf("range start %d is after its end %d", low, high)
+		}
+	case strings.HasPrefix(value, "<="):
+		high, err = number(value[2:])
+	case strings.HasPrefix(value, ">="):
+		low, err = number(value[2:])
+	case strings.HasPrefix(value, "<"):
+		high, err = number(value[1:])
+		high--
+	case strings.HasPrefix(value, ">"):
+		low, err = number(value[1:])
+		low++
+	default:
+		low, err = number(value)
+		high = low
+	}
+	return low, high, err
+}
+
+func memberCountsBetween(low, high int) []int {
+	var counts []int
+	for i := max(low, 1); i <= min(high, maxMemberCount); i++ {
+		counts = append(counts, i)
+	}
+	return counts
+}
+
+// runSearch answers plain queries from the search index and structured ones
+// by combining field-restricted index lookups with filter predicates.
+func runSearch(query string, limit int) ([]models.SearchResult, error) {
+	if !isStructured(query) {
+		return searchAllData(query, limit), nil
+	}
+
+	sq, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	artists := dataStore.GetAllArtists()
+	if len(sq.Filters) > 0 {
+		artists = NewArtistFilter(sq.Filters).Filter(artists)
+	}
+	allowed := make(map[int]bool, len(artists))
+	for _, artist := range artists {
+		allowed[artist.ID] = true
+	}
+
+	// Every field term must match for an artist to stay in the result
+	var termResults []models.SearchResult
+	for _, term := range sq.Terms {
+		matched := make(map[int]bool)
+		for _, result := range dataStore.SearchField(term.Field, term.Value) {
+			if allowed[result.ArtistId] {
+				matched[result.ArtistId] = true
+				termResults = append(termResults, result)
+			}
+		}
+		allowed = matched
+	}
+
+	var candidates []models.SearchResult
+	switch {
+	case sq.Text != "":
+		candidates = searchAllData(sq.Text, 0)
+	case len(termResults) > 0:
+		candidates = termResults
+	default:
+		for _, artist := range artists {
+			candidates = append(candidates, models.SearchResult{
+				Text:        artist.Name,
+				Type:        store.TypeArtist,
+				ArtistName:  artist.Name,
+				Description: fmt.Sprintf("Band formed in %d", artist.CreationDate),
+				ArtistId:    artist.ID,
+				Score:       1,
+			})
+		}
+	}
+
+	var results []models.SearchResult
+	for _, result := range candidates {
+		if allowed[result.ArtistId] {
+			results = append(results, result)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// searchConcerts returns the concerts a query asks for, if any. A plain query
+// that reads as a date or date range, such as "concerts in December 2019",
+// lists every concert in it. A date: qualifier lists the concerts of the
+// matched artists, limited to matched locations when location: is used.
+func searchConcerts(query string, results []models.SearchResult) (*models.DateRange, []models.Concert) {
+	if !isStructured(query) {
+		dates, ok := parseDateRange(query)
+		if !ok {
+			return nil, nil
+		}
+		return &dates, dataStore.ConcertsBetween(dates)
+	}
+
+	sq, err := ParseSearchQuery(query)
+	if err != nil || sq.Dates == nil {
+		return nil, nil
+	}
+
+	byLocation := false
+	for _, term := range sq.Terms {
+		if term.Field == store.TypeLocation {
+			byLocation = true
+		}
+	}
+	artists := make(map[int]bool)
+	locations := make(map[int][]string)
+	for _, result := range results {
+		artists[result.ArtistId] = true
+		if result.Type == store.TypeLocation {
+			locations[result.ArtistId] = append(locations[result.ArtistId], result.Text)
+		}
+	}
+
+	var concerts []models.Concert
+	for _, concert := range dataStore.ConcertsBetween(*sq.Dates) {
+		if !artists[concert.ArtistId] {
+			continue
+		}
+		if byLocation && !inAny(concert.Place, locations[concert.ArtistId]) {
+			continue
+		}
+		concerts = append(concerts, concert)
+	}
+	return sq.Dates, concerts
+}
+
+// inAny reports whether place lies within any of the matched locations,
+// which may be regions, countries or continents.
+func inAny(place models.Place, matched []string) bool {
+	for _, name := range matched {
+		if place.In(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"groupie/store"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value     string
+		low, high int
+		wantErr   string
+	}{
+		{value: "1970", low: 1970, high: 1970},
+		{value: "1970..1975", low: 1970, high: 1975},
+		{value: "1970..", low: 1970, high: 9999},
+		{value: "..1975", low: 0, high: 1975},
+		{value: "<1980", low: 0, high: 1979},
+		{value: "<=1980", low: 0, high: 1980},
+		{value: ">4", low: 5, high: 9999},
+		{value: ">=4", low: 4, high: 9999},
+		{value: "abc", wantErr: `"abc" is not a number`},
+		{value: "1975..1970", wantErr: "range start 1975 is after its end 1970"},
+		{value: "..", wantErr: "range needs at least one bound"},
+		{value: "1970..x", wantErr: `"x" is not a number`},
+		{value: ">=", wantErr: `"" is not a number`},
+	}
+
+	for _, tt := range tests {
+		low, high, err := parseRange(tt.value)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("parseRange(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRange(%q) unexpected error: %v", tt.value, err)
+			continue
+		}
+		if low != tt.low || high != tt.high {
+			t.Errorf("parseRange(%q) = %d, %d, want %d, %d", tt.value, low, high, tt.low, tt.high)
+		}
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		text  string
+		terms []fieldTerm
+	}{
+		{query: "queen", text: "queen"},
+		{
+			query: "member:freddie location:london queen",
+			text:  "queen",
+			terms: []fieldTerm{
+				{Field: store.TypeMember, Value: "freddie"},
+				{Field: store.TypeLocation, Value: "london"},
+			},
+		},
+		{
+			query: `Artist:"pink floyd" formed:1965..1970`,
+			terms: []fieldTerm{{Field: store.TypeArtist, Value: "pink floyd"}},
+		},
+		{query: "album:<1980 members:>=4"},
+		{query: `"freddie mercury" formed:1970..`, text: "freddie mercury"},
+		{query: "http://x", text: "http://x"},
+		{query: "note:live queen", text: "note:live queen"},
+	}
+
+	for _, tt := range tests {
+		sq, err := ParseSearchQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q) unexpected error: %v", tt.query, err)
+			continue
+		}
+		if sq.Text != tt.text {
+			t.Errorf("ParseSearchQuery(%q).Text = %q, want %q", tt.query, sq.Text, tt.text)
+		}
+		if !reflect.DeepEqual(sq.Terms, tt.terms) {
+			t.Errorf("ParseSearchQuery(%q).Terms = %v, want %v", tt.query, sq.Terms, tt.terms)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{query: "formed:abc", wantErr: `"formed:abc": "abc" is not a number`},
+		{query: "formed:1975..1970", wantErr: `"formed:1975..1970": range start 1975 is after its end 1970`},
+		{query: `artist:"pink floyd`, wantErr: "unterminated quote"},
+		{query: "members:>8", wantErr: `"members:>8": member count must be between 1 and 8`},
+		{query: "member:", wantErr: `"member:": qualifier member: needs a value`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseSearchQuery(tt.query)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("ParseSearchQuery(%q) error = %v, want %q", tt.query, err, tt.wantErr)
+		}
+	}
+}
+
+func TestIsStructured(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{query: "queen", want: false},
+		{query: "http://x", want: false},
+		{query: "note:live", want: false},
+		{query: "member:freddie", want: true},
+		{query: "FORMED:1970", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := isStructured(tt.query); got != tt.want {
+			t.Errorf("isStructured(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
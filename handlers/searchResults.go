@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"groupie/models"
+	"groupie/store"
+	"groupie/utils"
+)
+
+// defaultTypeOrder is the order matches are listed in within a group, and
+// how groups with equal scores are ranked, unless a request sets "order".
+var defaultTypeOrder = []string{
+	store.TypeArtist,
+	store.TypeMember,
+	store.TypeLocation,
+	store.TypeCreationDate,
+	store.TypeFirstAlbum,
+}
+
+var searchTypeNames = map[string]string{
+	"artist":   store.TypeArtist,
+	"member":   store.TypeMember,
+	"location": store.TypeLocation,
+	"formed":   store.TypeCreationDate,
+	"album":    store.TypeFirstAlbum,
+}
+
+// parseTypeOrder reads a comma-separated list such as "member,location".
+// Listed types come first in the given order, the rest keep the default order.
+func parseTypeOrder(value string) ([]string, error) {
+	if value == "" {
+		return defaultTypeOrder, nil
+	}
+
+	var order []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		resultType, ok := searchTypeNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown result type %q in order; use artist, member, location, formed or album", name)
+		}
+		if !seen[resultType] {
+			seen[resultType] = true
+			order = append(order, resultType)
+		}
+	}
+	for _, resultType := range defaultTypeOrder {
+		if !seen[resultType] {
+			order = append(order, resultType)
+		}
+	}
+	return order, nil
+}
+
+type searchParams struct {
+	query string
+	page  int
+	limit int
+	order []string
+}
+
+func parseSearchParams(r *http.Request) (searchParams, error) {
+	q := r.URL.Query()
+	params := searchParams{
+		query: strings.TrimSpace(q.Get("q")),
+		page:  utils.ParseIntDefault(q.Get("page"), 1),
+		limit: utils.ParseIntDefault(q.Get("limit"), defaultPageSize),
+	}
+	if params.page < 1 || params.limit < 1 || params.limit > maxPageSize {
+		return params, fmt.Errorf("page must be at least 1 and limit between 1 and %d", maxPageSize)
+	}
+
+	order, err := parseTypeOrder(q.Get("order"))
+	if err != nil {
+		return params, err
+	}
+	params.order = order
+	return params, nil
+}
+
+// groupResults merges the matches of each artist into one group. A value
+// matched more than once, such as a location visited twice, is kept once.
+func groupResults(results []models.SearchResult, order []string) []models.SearchGroup {
+	rank := make(map[string]int, len(order))
+	for i, resultType := range order {
+		rank[resultType] = i
+	}
+
+	var groups []models.SearchGroup
+	index := make(map[int]int)
+	seen := make(map[string]bool)
+	for _, result := range results {
+		key := fmt.Sprintf("%d|%s|%s", result.ArtistId, result.Type, result.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		i, ok := index[result.ArtistId]
+		if !ok {
+			i = len(groups)
+			index[result.ArtistId] = i
+			groups = append(groups, models.SearchGroup{
+				ArtistId:   result.ArtistId,
+				ArtistName: result.ArtistName,
+			})
+		}
+		group := &groups[i]
+		group.Matches = append(group.Matches, result)
+		group.MatchCount++
+		if result.Score > group.Score {
+			group.Score = result.Score
+		}
+	}
+
+	bestRank := func(g models.SearchGroup) int {
+		return rank[g.Matches[0].Type]
+	}
+	for i := range groups {
+		matches := groups[i].Matches
+		sort.SliceStable(matches, func(a, b int) bool {
+			if rank[matches[a].Type] != rank[matches[b].Type] {
+				return rank[matches[a].Type] < rank[matches[b].Type]
+			}
+			return matches[a].Score > matches[b].Score
+		})
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		if groups[a].Score != groups[b].Score {
+			return groups[a].Score > groups[b].Score
+		}
+		if bestRank(groups[a]) != bestRank(groups[b]) {
+			return bestRank(groups[a]) < bestRank(groups[b])
+		}
+		return groups[a].ArtistName < groups[b].ArtistName
+	})
+	return groups
+}
+
+// buildSearchPage runs the query and returns one page of artist groups and
+// of any concerts the query lists.
+func buildSearchPage(params searchParams) (models.SearchPage, error) {
+	page := models.SearchPage{
+		Query:  params.query,
+		Groups: []models.SearchGroup{},
+		Page:   params.page,
+		Limit:  params.limit,
+	}
+	if params.query == "" {
+		return page, nil
+	}
+
+	results, err := runSearch(params.query, 0)
+	if err != nil {
+		return page, err
+	}
+
+	dates, concerts := searchConcerts(params.query, results)
+	page.DateRange = dates
+	page.TotalConcerts = len(concerts)
+
+	groups := groupResults(results, params.order)
+	for _, group := range groups {
+		page.TotalMatches += group.MatchCount
+	}
+	page.Total = len(groups)
+	// Concerts are paged alongside the artist groups, so a page may run out
+	// of one before the other
+	page.TotalPages = max(utils.TotalPages(len(groups), params.limit), utils.TotalPages(len(concerts), params.limit))
+
+	start, end := utils.PageBounds(len(groups), params.page, params.limit)
+	page.Groups = append(page.Groups, groups[start:end]...)
+	start, end = utils.PageBounds(len(concerts), params.page, params.limit)
+	page.Concerts = concerts[start:end]
+	return page, nil
+}
+
+// pageURL links to another page of the current search, keeping q and order.
+func pageURL(r *http.Request, page int) string {
+	values := url.Values{}
+	for key, vals := range r.URL.Query() {
+		values[key] = vals
+	}
+	values.Set("page", fmt.Sprint(page))
+	return r.URL.Path + "?" + values.Encode()
+}
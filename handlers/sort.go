@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"groupie/models"
+	"groupie/utils"
+)
+
+// sortOption is a named artist ordering. value returns the key to order by
+// and false when the artist has none; such artists are listed last in either
+// direction. Name ordering has no value and compares names instead. desc is
+// empty for orderings that only make sense ascending.
+type sortOption struct {
+	name      string
+	asc, desc string
+	value     func(artist models.Artist, s ArtistSort) (float64, bool)
+}
+
+var sortOptions = []sortOption{
+	{name: "name", asc: "Name (A-Z)", desc: "Name (Z-A)"},
+	{name: "creation", asc: "Oldest formed", desc: "Newest formed", value: func(a models.Artist, _ ArtistSort) (float64, bool) {
+		return float64(a.CreationDate), a.CreationDate > 0
+	}},
+	{name: "album", asc: "Oldest first album", desc: "Newest first album", value: firstAlbumValue},
+	{name: "members", asc: "Fewest members", desc: "Most members", value: func(a models.Artist, _ ArtistSort) (float64, bool) {
+		return float64(len(a.Members)), true
+	}},
+	{name: "concerts", asc: "Fewest concerts", desc: "Most concerts", value: func(a models.Artist, _ ArtistSort) (float64, bool) {
+		return float64(len(a.Concerts)), true
+	}},
+	{name: "recent", asc: "Least recent concert", desc: "Most recent concert", value: latestConcertValue},
+	{name: "distance", asc: "Nearest concert", value: nearestConcertValue},
+}
+
+func firstAlbumValue(artist models.Artist, _ ArtistSort) (float64, bool) {
+	t, err := albumDateKey(artist.FirstAlbum)
+	if err != nil {
+		return 0, false
+	}
+	return float64(t), true
+}
+
+// albumDateKey orders "DD-MM-YYYY" first album dates as YYYYMMDD.
+func albumDateKey(date string) (int, error) {
+	parts := strings.Split(date, "-")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid album date %q", date)
+	}
+	return strconv.Atoi(parts[2] + parts[1] + parts[0])
+}
+
+func latestConcertValue(artist models.Artist, _ ArtistSort) (float64, bool) {
+	if len(artist.Concerts) == 0 {
+		return 0, false
+	}
+	return float64(artist.Concerts[len(artist.Concerts)-1].Date.Unix()), true
+}
+
+// nearestConcertValue is the distance in km from the sort point to the
+// closest concert location with cached coordinates. Sorting only reads the
+// cache; locations not yet geocoded are ignored until the loader reaches
+// them.
+func nearestConcertValue(artist models.Artist, s ArtistSort) (float64, bool) {
+	nearest, found := math.Inf(1), false
+	for _, location := range artist.LocationsList {
+		coords, ok := dataStore.CachedCoordinates(location)
+		if !ok {
+			continue
+		}
+		nearest = min(nearest, utils.Distance(s.Near, coords))
+		found = true
+	}
+	return nearest, found
+}
+
+// ArtistSort is a parsed "sort" parameter such as "name" or "-creation",
+// where a leading minus reverses the order. Distance ordering also needs a
+// "near=lat,lon" point.
+type ArtistSort struct {
+	Option string
+	Desc   bool
+	Near   models.Coordinates
+	near   string
+}
+
+func ParseArtistSort(form url.Values) (ArtistSort, error) {
+	var s ArtistSort
+	value := strings.TrimSpace(form.Get("sort"))
+	if value == "" {
+		return s, nil
+	}
+	s.Option = strings.TrimPrefix(value, "-")
+	s.Desc = s.Option != value
+
+	option, ok := findSortOption(s.Option)
+	if !ok {
+		names := make([]string, len(sortOptions))
+		for i, o := range sortOptions {
+			names[i] = o.name
+		}
+		return s, fmt.Errorf("unknown sort %q; use one of %s, optionally prefixed with -", s.Option, strings.Join(names, ", "))
+	}
+	if s.Desc && option.desc == "" {
+		return s, fmt.Errorf("sort %s cannot be reversed", s.Option)
+	}
+
+	if s.Option == "distance" {
+		s.near = strings.TrimSpace(form.Get("near"))
+		lat, lon, found := strings.Cut(s.near, ",")
+		var errLat, errLon error
+		s.Near.Lat, errLat = strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		s.Near.Lon, errLon = strconv.ParseFloat(strings.TrimSpace(lon), 64)
+		if !found || errLat != nil || errLon != nil || math.Abs(s.Near.Lat) > 90 || math.Abs(s.Near.Lon) > 180 {
+			return s, fmt.Errorf("sort=distance needs near=lat,lon, got %q", s.near)
+		}
+	}
+	return s, nil
+}
+
+func findSortOption(name string) (sortOption, bool) {
+	for _, option := range sortOptions {
+		if option.name == name {
+			return option, true
+		}
+	}
+	return sortOption{}, false
+}
+
+// Value returns the sort parameter that parses back into s.
+func (s ArtistSort) Value() string {
+	if s.Desc {
+		return "-" + s.Option
+	}
+	return s.Option
+}
+
+// Encode adds the sort parameters to values, if a sort is set.
+func (s ArtistSort) Encode(values url.Values) {
+	if s.Option == "" {
+		return
+	}
+	values.Set("sort", s.Value())
+	if s.Option == "distance" {
+		values.Set("near", s.near)
+	}
+}
+
+// Apply orders artists in place. Without a sort they keep the upstream order;
+// ties are broken by name.
+func (s ArtistSort) Apply(artists []models.Artist) {
+	option, ok := findSortOption(s.Option)
+	if !ok {
+		return
+	}
+
+	type entry struct {
+		value float64
+		known bool
+	}
+	keys := make(map[int]entry, len(artists))
+	if option.value != nil {
+		for _, artist := range artists {
+			value, known := option.value(artist, s)
+			keys[artist.ID] = entry{value, known}
+		}
+	}
+
+	slices.SortStableFunc(artists, func(a, b models.Artist) int {
+		ka, kb := keys[a.ID], keys[b.ID]
+		var c int
+		switch {
+		case option.value == nil:
+			c = cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case ka.known != kb.known:
+			// Unknown values go last whatever the direction
+			if ka.known {
+				return -1
+			}
+			return 1
+		default:
+			c = cmp.Compare(ka.value, kb.value)
+		}
+		if s.Desc {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+		return c
+	})
+}
+
+// State lists every ordering for the sort selector.
+func (s ArtistSort) State() models.SortState {
+	state := models.SortState{
+		Value:   s.Value(),
+		Near:    s.near,
+		Options: []models.SortOption{{Value: "", Label: "Default order", Selected: s.Option == ""}},
+	}
+	for _, option := range sortOptions {
+		state.Options = append(state.Options, models.SortOption{
+			Value:    option.name,
+			Label:    option.asc,
+			Selected: s.Option == option.name && !s.Desc,
+		})
+		if option.desc != "" {
+			state.Options = append(state.Options, models.SortOption{
+				Value:    "-" + option.name,
+				Label:    option.desc,
+				Selected: s.Option == option.name && s.Desc,
+			})
+		}
+	}
+	return state
+}
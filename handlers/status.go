@@ -0,0 +1,13 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// StatusHandler reports when the dataset was last refreshed from upstream and
+// whether that refresh succeeded.
+func StatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dataStore.GetRefreshStatus())
+}
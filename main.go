@@ -1,8 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"groupie/handlers"
@@ -19,6 +26,25 @@ func setupServer() *http.ServeMux {
 	mux.HandleFunc("/search", handlers.SearchHandler)
 	mux.HandleFunc("/filter", handlers.FilterHandler)
 	mux.HandleFunc("/api/coordinates", handlers.GetLocationCoordinates)
+	mux.HandleFunc("/api/status", handlers.StatusHandler)
+
+	mux.HandleFunc("GET /api/v1/artists", handlers.APIArtistsHandler)
+	mux.HandleFunc("GET /api/v1/artists/{id}", handlers.APIArtistHandler)
+	mux.HandleFunc("GET /api/v1/artists/{id}/tours", handlers.APIArtistToursHandler)
+	mux.HandleFunc("GET /api/v1/locations", handlers.APILocationsHandler)
+	mux.HandleFunc("GET /api/v1/filters", handlers.APIFiltersHandler)
+	mux.HandleFunc("GET /api/v1/search", handlers.APISearchHandler)
+	// Without these, other methods would fall through to "/" and get a 404
+	for _, path := range []string{
+		"/api/v1/artists",
+		"/api/v1/artists/{id}",
+		"/api/v1/artists/{id}/tours",
+		"/api/v1/locations",
+		"/api/v1/filters",
+		"/api/v1/search",
+	} {
+		mux.HandleFunc(path, handlers.MethodNotAllowed(http.MethodGet, http.MethodHead))
+	}
 
 	fileServer := http.FileServer(http.Dir("static"))
 	mux.Handle("/static/", http.StripPrefix("/static/", fileServer))
@@ -26,25 +52,129 @@ func setupServer() *http.ServeMux {
 	return mux
 }
 
-func main() {
-	dataStore := store.New()
+func newSource(kind, apiURL, dataDir string) (store.Source, error) {
+	switch kind {
+	case "api":
+		return store.NewHTTPSource(apiURL), nil
+	case "dir":
+		if dataDir == "" {
+			return nil, fmt.Errorf("source %q requires -data-dir", kind)
+		}
+		return store.NewDirSource(dataDir), nil
+	case "memory":
+		return store.NewFixtureSource(), nil
+	default:
+		return nil, fmt.Errorf("unknown source %q (want api, dir or memory)", kind)
+	}
+}
+
+// warmStart serves the last saved snapshot right away when there is one and
+// refreshes from upstream in the background. Without a usable snapshot it
+// falls back to a blocking load.
+func warmStart(dataStore *store.DataStore) {
+	if dataStore.SnapshotPath != "" {
+		savedAt, err := dataStore.LoadSnapshot(dataStore.SnapshotPath)
+		if err == nil {
+			log.Printf("Loaded snapshot from %s (saved %s)", dataStore.SnapshotPath, savedAt.Format(time.RFC3339))
+			go func() {
+				if err := dataStore.Refresh(); err != nil {
+					log.Printf("Background refresh failed, keeping snapshot data: %v", err)
+				}
+			}()
+			return
+		}
+		log.Printf("No usable snapshot: %v", err)
+	}
+
 	if err := dataStore.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize data store: %v", err)
 	}
+}
+
+// newGeocoder builds the configured geocoder. Nominatim is wrapped in a single
+// rate limiter shared by on-demand and background lookups.
+func newGeocoder(kind, nominatimURL string) (store.Geocoder, error) {
+	nominatim := store.NewRateLimitedGeocoder(store.NewNominatimGeocoder(nominatimURL), store.NominatimInterval)
+	switch kind {
+	case "nominatim":
+		return nominatim, nil
+	case "static":
+		return store.NewStaticGeocoder(), nil
+	case "static+nominatim":
+		return store.ChainGeocoder{store.NewStaticGeocoder(), nominatim}, nil
+	case "fake":
+		return &store.FakeGeocoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown geocoder %q (want nominatim, static, static+nominatim or fake)", kind)
+	}
+}
+
+func main() {
+	sourceKind := flag.String("source", "api", "upstream data source: api, dir or memory")
+	apiURL := flag.String("api-url", store.DefaultAPIURL, "base URL of the Groupie Trackers API (source=api)")
+	dataDir := flag.String("data-dir", "", "directory of upstream JSON files (source=dir)")
+	refreshInterval := flag.Duration("refresh", time.Hour, "interval between background refreshes from the source (0 disables)")
+	snapshotPath := flag.String("snapshot", "cache/snapshot.json", "file to warm start from and save the dataset to (empty disables)")
+	coordCachePath := flag.String("geocode-cache", "cache/coordinates.json", "file to persist geocoded coordinates in (empty disables)")
+	coordTTL := flag.Duration("geocode-ttl", 90*24*time.Hour, "age after which cached coordinates are geocoded again (0 keeps them forever)")
+	geocoderKind := flag.String("geocoder", "static+nominatim", "geocoder: nominatim, static, static+nominatim or fake")
+	nominatimURL := flag.String("nominatim-url", store.DefaultNominatimURL, "base URL of the Nominatim server")
+	flag.Parse()
+
+	source, err := newSource(*sourceKind, *apiURL, *dataDir)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	geocoder, err := newGeocoder(*geocoderKind, *nominatimURL)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	dataStore := store.New(source)
+	dataStore.Geocoder = geocoder
+	dataStore.SnapshotPath = *snapshotPath
+	dataStore.CoordinateCachePath = *coordCachePath
+	dataStore.CoordinateTTL = *coordTTL
+	if err := dataStore.LoadCoordinateCache(); err != nil {
+		log.Printf("Ignoring coordinate cache: %v", err)
+	}
+	warmStart(dataStore)
+	stopRefresher := dataStore.StartRefresher(*refreshInterval)
 
 	handlers.Initialize(dataStore)
 
 	mux := setupServer()
 	server := &http.Server{
 		Addr:         port,
-		Handler:      mux,
+		Handler:      handlers.RequestID(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	log.Printf("Server starting on http://localhost%s", port)
-	if err := server.ListenAndServe(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errChan := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on http://localhost%s", port)
+		errChan <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		stopRefresher()
 		log.Fatalf("Server failed to start: %v", err)
+	case <-ctx.Done():
+	}
+
+	// Stop refreshing first so no reload runs while requests drain
+	log.Println("Shutting down")
+	stopRefresher()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Shutdown did not complete: %v", err)
 	}
 }
@@ -1,7 +1,10 @@
 package models
 
 type ApiIndex struct {
-	Artists string `json:"artists"`
+	Artists   string `json:"artists"`
+	Locations string `json:"locations"`
+	Dates     string `json:"dates"`
+	Relation  string `json:"relation"`
 }
 
 type Artist struct {
@@ -11,14 +14,20 @@ type Artist struct {
 	Members      []string `json:"members"`
 	CreationDate int      `json:"creationDate"`
 	FirstAlbum   string   `json:"firstAlbum"`
-	Locations    string `json:"locations"`
-	ConcertDates string `json:"concertDates"`
-	Relations    string `json:"relations"`
-
-	LocationsList        []string            `json:"-"`
-	LocationStatesCities map[string][]string `json:"-"`
-	DatesList            []string            `json:"-"`
-	RelationsList        map[string][]string `json:"-"`
+	Locations    string   `json:"locations"`
+	ConcertDates string   `json:"concertDates"`
+	Relations    string   `json:"relations"`
+
+	// Concerts are in date order. The lists below are derived from them for
+	// display.
+	Concerts             []Concert           `json:"concerts"`
+	LocationsList        []string            `json:"locationsList"`
+	LocationStatesCities map[string][]string `json:"locationStatesCities"`
+	DatesList            []string            `json:"datesList"`
+	RelationsList        map[string][]string `json:"relationsList"`
+
+	// Tours are detected from the concerts when the artist is looked up.
+	Tours []Tour `json:"tours,omitempty"`
 }
 
 type ArtistCard struct {
@@ -27,14 +36,26 @@ type ArtistCard struct {
 	Image string `json:"image"`
 }
 
-type Location struct {
-	Locations []string `json:"locations"`
+type Relation struct {
+	ID             int                 `json:"id"`
+	DatesLocations map[string][]string `json:"datesLocations"`
+}
+
+type RelationIndex struct {
+	Index []Relation `json:"index"`
 }
 
-type Date struct {
-	Dates []string `json:"dates"`
+type ArtistPage struct {
+	Artists    []ArtistCard `json:"artists"`
+	Sort       string       `json:"sort,omitempty"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	Total      int          `json:"total"`
+	TotalPages int          `json:"totalPages"`
+	Next       string       `json:"next,omitempty"`
 }
 
-type Relation struct {
-	DatesLocations map[string][]string `json:"datesLocations"`
+type LocationSummary struct {
+	Location  string `json:"location"`
+	ArtistIDs []int  `json:"artistIds"`
 }
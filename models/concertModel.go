@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// ConcertDateLayout is how concert dates are displayed.
+const ConcertDateLayout = "January 2, 2006"
+
+// Concert is one show: an artist playing a location on a date. The embedded
+// Place is the location resolved in the gazetteer; Coordinates is set once
+// the location has been geocoded.
+type Concert struct {
+	ArtistId   int    `json:"artistId"`
+	ArtistName string `json:"artistName"`
+	Place
+	Date        time.Time    `json:"date"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+}
+
+func (c Concert) DisplayDate() string {
+	return c.Date.Format(ConcertDateLayout)
+}
+
+// MarshalJSON adds the display date to the concert's fields.
+func (c Concert) MarshalJSON() ([]byte, error) {
+	type concert Concert
+	return json.Marshal(struct {
+		concert
+		DisplayDate string `json:"displayDate"`
+	}{concert(c), c.DisplayDate()})
+}
+
+// DateRange is the half-open interval [From, To). A zero bound is open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// MarshalJSON writes the bounds as dates and leaves out open ones.
+func (r DateRange) MarshalJSON() ([]byte, error) {
+	bounds := make(map[string]string, 2)
+	if !r.From.IsZero() {
+		bounds["from"] = r.From.Format("2006-01-02")
+	}
+	if !r.To.IsZero() {
+		bounds["to"] = r.To.Format("2006-01-02")
+	}
+	return json.Marshal(bounds)
+}
+
+func (r DateRange) Contains(t time.Time) bool {
+	return (r.From.IsZero() || !t.Before(r.From)) && (r.To.IsZero() || t.Before(r.To))
+}
+
+// Tour is a run of one artist's concerts that are close together in time and
+// place. DistanceKm sums the legs between consecutive stops with known
+// coordinates; Complete is false while some stops are not yet geocoded.
+type Tour struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Stops      []Concert `json:"stops"`
+	DistanceKm float64   `json:"distanceKm"`
+	Complete   bool      `json:"complete"`
+}
+
+// Continents lists the continents the tour visited, in the order it first
+// reached them.
+func (t Tour) Continents() []string {
+	var continents []string
+	for _, stop := range t.Stops {
+		if stop.Continent != "" && !slices.Contains(continents, stop.Continent) {
+			continents = append(continents, stop.Continent)
+		}
+	}
+	return continents
+}
@@ -1,18 +1,93 @@
 package models
 
 type FilterData struct {
-	Artists         []ArtistCard
-	UniqueLocations []string
-	SelectedFilters FilterParams
-	TotalResults    int
-	CurrentPath     string
-}
-
-type FilterParams struct {
-	MemberCounts   []int
-	Locations      []string
-	CreationStart  int
-	CreationEnd    int
-	AlbumStartYear int
-	AlbumEndYear   int
+	Artists []ArtistCard
+	FilterState
+	Sort         SortState
+	TotalResults int
+	CurrentPath  string
+
+	// Page is the page of Artists shown, counted from 1. First and Last are
+	// the positions of its first and last artist among all results.
+	Page, TotalPages int
+	First, Last      int
+	// Limit is the page size asked for, or 0 for the default.
+	Limit            int
+	PrevURL, NextURL string
+	// MoreURL is the JSON page after this one, for infinite scrolling.
+	MoreURL string
+}
+
+// SortState is the selected artist ordering and every available one. Near is
+// the "lat,lon" point used by the distance ordering.
+type SortState struct {
+	Value   string       `json:"value"`
+	Near    string       `json:"near,omitempty"`
+	Options []SortOption `json:"options"`
+}
+
+type SortOption struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Selected bool   `json:"selected"`
+}
+
+// FilterState describes every registered filter with its current selection,
+// how the active filters combine, and the query string that reproduces them.
+type FilterState struct {
+	Filters []FilterField `json:"filters"`
+	Match   string        `json:"match"`
+	Query   string        `json:"query"`
+}
+
+// Filter field kinds.
+const (
+	FilterKindChoice = "choice"
+	FilterKindRange  = "range"
+	FilterKindInput  = "input"
+)
+
+type FilterField struct {
+	Name    string         `json:"name"`
+	Label   string         `json:"label"`
+	Kind    string         `json:"kind"`
+	Param   string         `json:"param,omitempty"`
+	Options []FilterOption `json:"options,omitempty"`
+	Range   *FilterRange   `json:"range,omitempty"`
+	Inputs  []FilterInput  `json:"inputs,omitempty"`
+	Active  bool           `json:"active"`
+	Negated bool           `json:"negated"`
+}
+
+// FilterOption is a choice, or a decade bucket of a range. Level is the depth
+// of a nested choice. Count is how many artists would match with it, given
+// every other active filter; Disabled is set when none would and the option
+// is not selected.
+type FilterOption struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Level    int    `json:"level,omitempty"`
+	Selected bool   `json:"selected"`
+	Count    int    `json:"count"`
+	Disabled bool   `json:"disabled"`
+}
+
+// FilterRange is a slider between Min and Max; Start and End are the
+// selected bounds and are sent as StartParam and EndParam.
+type FilterRange struct {
+	StartParam string `json:"startParam"`
+	EndParam   string `json:"endParam"`
+	Min        int    `json:"min"`
+	Max        int    `json:"max"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+}
+
+// FilterInput is a free-form field such as a date; Type is the HTML input type.
+type FilterInput struct {
+	Param       string `json:"param"`
+	Label       string `json:"label"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Placeholder string `json:"placeholder,omitempty"`
 }
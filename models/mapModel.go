@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Coordinates struct {
 	Lat     float64 `json:"lat"`
 	Lon     float64 `json:"lon"`
@@ -10,3 +12,37 @@ type NominatimResponse struct {
 	Lat string `json:"lat"`
 	Lon string `json:"lon"`
 }
+
+const (
+	StatusResolved = "resolved"
+	StatusPending  = "pending"
+	StatusFailed   = "failed"
+)
+
+type LocationStatus struct {
+	Location    string       `json:"location"`
+	Status      string       `json:"status"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+	Reason      string       `json:"reason,omitempty"`
+	RetryAfter  *time.Time   `json:"retryAfter,omitempty"`
+	// Dates are the days concerts were played there, as "2006-01-02".
+	Dates []string `json:"dates,omitempty"`
+}
+
+// LocationCoordinates describes an artist's map. Locations are listed in the
+// order they were first played and Stops in the order they were played.
+type LocationCoordinates struct {
+	Complete  bool             `json:"complete"`
+	Locations []LocationStatus `json:"locations"`
+	Stops     []MapStop        `json:"stops"`
+}
+
+// MapStop is one concert on the map. Tour is the index of its tour in the
+// artist's tours; Coordinates is nil until the location is geocoded.
+type MapStop struct {
+	Location    string       `json:"location"`
+	Date        string       `json:"date"`
+	DisplayDate string       `json:"displayDate"`
+	Tour        int          `json:"tour"`
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "strings"
+
+// Place is a concert location resolved to every level of the location
+// hierarchy: continent, country, state or region, and city. City is empty
+// when the location names a whole region, State when it is unknown.
+type Place struct {
+	Location  string `json:"location"`
+	City      string `json:"city,omitempty"`
+	State     string `json:"state,omitempty"`
+	Country   string `json:"country,omitempty"`
+	Continent string `json:"continent,omitempty"`
+}
+
+// Region returns the state with its country, as in "California, Usa", or ""
+// when the state is unknown.
+func (p Place) Region() string {
+	if p.State == "" {
+		return ""
+	}
+	return p.State + ", " + p.Country
+}
+
+// In reports whether the place is, or lies within, the named location,
+// region, country or continent. A region may be named with or without its
+// country. Names are compared case-insensitively.
+func (p Place) In(name string) bool {
+	for _, level := range []string{p.Location, p.Region(), p.State, p.Country, p.Continent} {
+		if level != "" && strings.EqualFold(level, name) {
+			return true
+		}
+	}
+	return false
+}
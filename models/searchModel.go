@@ -1,14 +1,76 @@
 package models
 
+import (
+	"html"
+	"html/template"
+	"strings"
+)
+
 type SearchResult struct {
-	Text        string `json:"text"`
-	Type        string `json:"type"`
-	ArtistName  string `json:"artistName"`
-	Description string `json:"description"`
-	ArtistId    int    `json:"artistId,omitempty"`
+	Text        string       `json:"text"`
+	Type        string       `json:"type"`
+	ArtistName  string       `json:"artistName"`
+	Description string       `json:"description"`
+	ArtistId    int          `json:"artistId,omitempty"`
+	Score       float64      `json:"score"`
+	Highlights  []MatchRange `json:"highlights,omitempty"`
+}
+
+// MatchRange is a matched part of SearchResult.Text, as rune offsets with an
+// exclusive end.
+type MatchRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// HighlightedText returns Text as escaped HTML with every matched range
+// wrapped in <mark>.
+func (r SearchResult) HighlightedText() template.HTML {
+	runes := []rune(r.Text)
+	var b strings.Builder
+	pos := 0
+	for _, span := range r.Highlights {
+		if span.Start < pos || span.End > len(runes) || span.Start >= span.End {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:span.Start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[span.Start:span.End])))
+		b.WriteString("</mark>")
+		pos = span.End
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:])))
+	return template.HTML(b.String())
+}
+
+// SearchGroup collects every match for one artist, best match first.
+type SearchGroup struct {
+	ArtistId   int            `json:"artistId"`
+	ArtistName string         `json:"artistName"`
+	Score      float64        `json:"score"`
+	MatchCount int            `json:"matchCount"`
+	Matches    []SearchResult `json:"matches"`
+}
+
+type SearchPage struct {
+	Query        string        `json:"query"`
+	Groups       []SearchGroup `json:"groups"`
+	Page         int           `json:"page"`
+	Limit        int           `json:"limit"`
+	Total        int           `json:"total"`
+	TotalMatches int           `json:"totalMatches"`
+	TotalPages   int           `json:"totalPages"`
+
+	// DateRange and Concerts are set when the query names concert dates.
+	// Concerts holds one page of the TotalConcerts found.
+	DateRange     *DateRange `json:"dateRange,omitempty"`
+	Concerts      []Concert  `json:"concerts,omitempty"`
+	TotalConcerts int        `json:"totalConcerts,omitempty"`
 }
 
 type SearchData struct {
-	Query   string
-	Results []SearchResult
+	SearchPage
+	Error   string
+	PrevURL string
+	NextURL string
 }
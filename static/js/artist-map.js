@@ -20,31 +20,136 @@ document.addEventListener('DOMContentLoaded', async function() {
     const urlParams = new URLSearchParams(window.location.search);
     const artistId = urlParams.get('id');
 
-    // Create a marker group
-//const markerGroup = L.featureGroup();
-
-    try {
-        // Fetch coordinates from our backend
-        const response = await fetch(`/api/coordinates?id=${artistId}`);
-        const coordinates = await response.json();
-
-        // Add markers for each location
-        coordinates.forEach(coord => {
-            const marker = L.marker([coord.lat, coord.lon])
-                .bindPopup(`<b>${coord.address}</b>`)
-                .addTo(map);
-         //   markerGroup.addLayer(marker);
+    const POLL_INTERVAL = 3000; // ms between polls while locations are pending
+    const MAX_POLLS = 60;
+    const PLAY_INTERVAL = 800; // ms per stop while the timeline plays
+    const placed = new Set();
+    let polls = 0;
+
+    // Tour paths are redrawn on every poll as more stops get coordinates;
+    // the progress path and marker follow the timeline
+    const pathLayer = L.layerGroup().addTo(map);
+    const progressPath = L.polyline([], { color: '#ff6b6b', weight: 4 }).addTo(map);
+    const currentMarker = L.circleMarker([0, 0], { radius: 8, color: '#ff6b6b', fillOpacity: 0.9 });
+    let stops = [];
+
+    const timeline = document.querySelector('.map-timeline');
+    const slider = timeline.querySelector('.timeline-slider');
+    const playButton = timeline.querySelector('.timeline-play');
+    const label = timeline.querySelector('.timeline-label');
+    let playTimer = null;
+
+    function escapeHTML(text) {
+        const div = document.createElement('div');
+        div.textContent = text;
+        return div.innerHTML;
+    }
+
+    function drawTours() {
+        pathLayer.clearLayers();
+        const tours = new Map();
+        stops.forEach(stop => {
+            if (!stop.coordinates) return;
+            if (!tours.has(stop.tour)) tours.set(stop.tour, []);
+            tours.get(stop.tour).push([stop.coordinates.lat, stop.coordinates.lon]);
+        });
+        tours.forEach(points => {
+            L.polyline(points, { color: '#45b7d1', weight: 2, opacity: 0.6, dashArray: '6 6' }).addTo(pathLayer);
         });
+    }
+
+    // showStop moves the timeline to stops[index], drawing the path up to it,
+    // and pans there when pan is set
+    function showStop(index, pan) {
+        const stop = stops[index];
+        if (!stop) return;
+        slider.value = index;
+        label.textContent = `${stop.displayDate} - ${stop.location}`;
+
+        const points = stops.slice(0, index + 1)
+            .filter(s => s.coordinates && s.tour === stop.tour)
+            .map(s => [s.coordinates.lat, s.coordinates.lon]);
+        progressPath.setLatLngs(points);
+
+        if (stop.coordinates) {
+            currentMarker.setLatLng([stop.coordinates.lat, stop.coordinates.lon]).addTo(map);
+            if (pan) map.panTo([stop.coordinates.lat, stop.coordinates.lon]);
+        } else {
+            currentMarker.remove();
+        }
+    }
 
-        // Only adjust bounds if we have markers
-        // if (markerGroup.getLayers().length > 0) {
-        //     markerGroup.addTo(map);
-        //     map.fitBounds(markerGroup.getBounds(), {
-        //         padding: [50, 50],
-        //         maxZoom: 5  // Don't zoom in too far when fitting bounds
-        //     });
-        // }
-    } catch (error) {
-        console.error('Error fetching coordinates:', error);
+    function stopPlaying() {
+        clearInterval(playTimer);
+        playTimer = null;
+        playButton.textContent = 'Play';
     }
-});
\ No newline at end of file
+
+    playButton.addEventListener('click', () => {
+        if (playTimer) {
+            stopPlaying();
+            return;
+        }
+        if (Number(slider.value) >= stops.length - 1) {
+            showStop(0, true);
+        }
+        playButton.textContent = 'Pause';
+        playTimer = setInterval(() => {
+            const next = Number(slider.value) + 1;
+            if (next >= stops.length) {
+                stopPlaying();
+                return;
+            }
+            showStop(next, true);
+        }, PLAY_INTERVAL);
+    });
+
+    slider.addEventListener('input', () => {
+        stopPlaying();
+        showStop(Number(slider.value), true);
+    });
+
+    async function loadCoordinates() {
+        try {
+            // Fetch coordinates from our backend; missing ones are resolved server-side
+            const response = await fetch(`/api/coordinates?id=${artistId}`);
+            const data = await response.json();
+            const { complete, locations } = data;
+
+            // Add markers for each newly resolved location
+            locations.forEach(location => {
+                if (location.status === 'failed' && !placed.has(location.location)) {
+                    console.warn(`No coordinates for ${location.location}: ${location.reason}`);
+                }
+                if (location.status !== 'resolved' || placed.has(location.location)) {
+                    return;
+                }
+                const coord = location.coordinates;
+                const dates = (location.dates || []).map(escapeHTML).join('<br>');
+                L.marker([coord.lat, coord.lon])
+                    .bindPopup(`<b>${escapeHTML(coord.address)}</b><br>${dates}`)
+                    .addTo(map);
+                placed.add(location.location);
+            });
+
+            stops = data.stops || [];
+            drawTours();
+            if (stops.length > 0) {
+                const first = timeline.hidden;
+                timeline.hidden = false;
+                slider.max = stops.length - 1;
+                showStop(first ? 0 : Number(slider.value), false);
+            }
+
+            // Keep polling until every location is resolved or has failed
+            polls++;
+            if (!complete && polls < MAX_POLLS) {
+                setTimeout(loadCoordinates, POLL_INTERVAL);
+            }
+        } catch (error) {
+            console.error('Error fetching coordinates:', error);
+        }
+    }
+
+    loadCoordinates();
+});
@@ -8,46 +8,56 @@ document.addEventListener('DOMContentLoaded', () => {
         });
     }
 
-    // Setup range slider functionality
-    function setupRangeSlider(startClass, endClass, startValueId, endValueId) {
-        const startSlider = document.querySelector(`.${startClass}`);
-        const endSlider = document.querySelector(`.${endClass}`);
-        const startValue = document.getElementById(startValueId);
-        const endValue = document.getElementById(endValueId);
-
-        if (startSlider && endSlider) {
-            startSlider.addEventListener('input', () => {
-                const start = parseInt(startSlider.value);
-                const end = parseInt(endSlider.value);
-                if (start > end) {
-                    startSlider.value = end;
-                }
-                startValue.textContent = startSlider.value;
+    // Fill the distance point from the browser and sort by it
+    const locationButton = document.querySelector('.use-location');
+    const nearInput = document.querySelector('.near-input');
+    const sortSelect = document.querySelector('.sort-select');
+    if (locationButton && nearInput && sortSelect) {
+        if (!navigator.geolocation) {
+            locationButton.style.display = 'none';
+        }
+        locationButton.addEventListener('click', () => {
+            navigator.geolocation.getCurrentPosition(position => {
+                const { latitude, longitude } = position.coords;
+                nearInput.value = `${latitude.toFixed(4)},${longitude.toFixed(4)}`;
+                sortSelect.value = 'distance';
+            }, error => {
+                console.error('Failed to get location:', error);
             });
+        });
+    }
+
+    // Keep the two handles of every range slider in order
+    document.querySelectorAll('.range-slider').forEach(slider => {
+        const startSlider = slider.querySelector('.range-start');
+        const endSlider = slider.querySelector('.range-end');
+        const startValue = slider.querySelector('.range-start-value');
+        const endValue = slider.querySelector('.range-end-value');
+
+        startSlider.addEventListener('input', () => {
+            if (parseInt(startSlider.value) > parseInt(endSlider.value)) {
+                startSlider.value = endSlider.value;
+            }
+            startValue.textContent = startSlider.value;
+        });
 
-            endSlider.addEventListener('input', () => {
-                const start = parseInt(startSlider.value);
-                const end = parseInt(endSlider.value);
-                if (end < start) {
-                    endSlider.value = start;
-                }
+        endSlider.addEventListener('input', () => {
+            if (parseInt(endSlider.value) < parseInt(startSlider.value)) {
+                endSlider.value = startSlider.value;
+            }
+            endValue.textContent = endSlider.value;
+        });
+
+        // A decade bucket moves both handles to that decade
+        slider.querySelectorAll('.range-bucket').forEach(bucket => {
+            bucket.addEventListener('click', () => {
+                const decade = parseInt(bucket.dataset.decade);
+                startSlider.value = Math.max(decade, parseInt(startSlider.min));
+                endSlider.value = Math.min(decade + 9, parseInt(endSlider.max));
+                startValue.textContent = startSlider.value;
                 endValue.textContent = endSlider.value;
+                slider.querySelectorAll('.range-bucket').forEach(b => b.classList.toggle('selected', b === bucket));
             });
-        }
-    }
-
-    // Setup both range sliders
-    setupRangeSlider(
-        'creation-start', 
-        'creation-end', 
-        'creation-start-value', 
-        'creation-end-value'
-    );
-    
-    setupRangeSlider(
-        'album-start', 
-        'album-end', 
-        'album-start-value', 
-        'album-end-value'
-    );
-});
\ No newline at end of file
+        });
+    });
+});
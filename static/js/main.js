@@ -45,4 +45,69 @@ document.addEventListener('DOMContentLoaded', () => {
     for (let i = 0; i < TOTAL_OBJECTS; i++) {
         backgroundContainer.appendChild(createFloatingObject());
     }
-});
\ No newline at end of file
+});
+// Infinite scroll: load the following pages of the artist grid as the
+// pagination links come into view
+document.addEventListener('DOMContentLoaded', () => {
+    const grid = document.querySelector('.artists-grid[data-more]');
+    const pagination = document.querySelector('.pagination');
+    if (!grid || !pagination || !('IntersectionObserver' in window)) return;
+
+    let next = grid.dataset.more;
+    let loading = false;
+
+    function createCard(artist) {
+        const card = document.createElement('div');
+        card.className = 'artist-card';
+
+        const link = document.createElement('a');
+        link.href = `/artist?id=${artist.id}`;
+        link.className = 'artist-link';
+
+        const imageContainer = document.createElement('div');
+        imageContainer.className = 'image-container';
+        const img = document.createElement('img');
+        img.src = artist.image;
+        img.alt = artist.name;
+        img.loading = 'lazy';
+        imageContainer.appendChild(img);
+
+        const info = document.createElement('div');
+        info.className = 'artist-info';
+        const name = document.createElement('h2');
+        name.textContent = artist.name;
+        info.appendChild(name);
+
+        link.append(imageContainer, info);
+        card.appendChild(link);
+        return card;
+    }
+
+    const observer = new IntersectionObserver(async (entries) => {
+        if (!entries[0].isIntersecting || loading || !next) return;
+        loading = true;
+        try {
+            const response = await fetch(next, { headers: { 'Accept': 'application/json' } });
+            if (!response.ok) throw new Error(`HTTP ${response.status}`);
+            const page = await response.json();
+            page.artists.forEach(artist => grid.appendChild(createCard(artist)));
+            next = page.next;
+            if (!next) {
+                observer.disconnect();
+                pagination.remove();
+            } else {
+                // Re-check in case the links are still in view
+                observer.unobserve(pagination);
+                observer.observe(pagination);
+            }
+        } catch (error) {
+            // Leave the pagination links for the user to follow instead
+            console.error('Failed to load more artists:', error);
+            observer.disconnect();
+        } finally {
+            loading = false;
+        }
+    }, { rootMargin: '400px' });
+
+    observer.observe(pagination);
+});
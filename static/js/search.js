@@ -1,3 +1,26 @@
+function escapeHTML(text) {
+    const div = document.createElement('div');
+    div.textContent = text;
+    return div.innerHTML;
+}
+
+// highlight wraps the matched ranges of a result in <mark>. Offsets are in
+// code points, so the text is split with Array.from rather than indexed.
+function highlight(text, ranges) {
+    const chars = Array.from(text);
+    let html = '';
+    let pos = 0;
+    (ranges || []).forEach(({ start, end }) => {
+        if (start < pos || end > chars.length || start >= end) {
+            return;
+        }
+        html += escapeHTML(chars.slice(pos, start).join(''));
+        html += `<mark>${escapeHTML(chars.slice(start, end).join(''))}</mark>`;
+        pos = end;
+    });
+    return html + escapeHTML(chars.slice(pos).join(''));
+}
+
 document.addEventListener('DOMContentLoaded', () => {
     const searchInput = document.querySelector('.search-input');
     const suggestionsList = document.querySelector('.suggestions-list');
@@ -13,27 +36,36 @@ document.addEventListener('DOMContentLoaded', () => {
             return;
         }
 
-        fetch(`/search?q=${encodeURIComponent(query)}`, {
-            headers: {
-                'X-Requested-With': 'XMLHttpRequest'
-            }
-        })
+        fetch(`/api/v1/search?q=${encodeURIComponent(query)}&limit=10`)
         .then(response => response.json())
-        .then(results => {
-            if (results.length === 0) {
+        .then(page => {
+            const groups = page.groups || [];
+            const concerts = page.concerts || [];
+            if (groups.length === 0 && concerts.length === 0) {
                 suggestionsList.innerHTML = '';
                 searchContainer.style.display = 'none';
                 return;
             }
 
             searchContainer.style.display = 'block';
-            // Render all results without limiting to 5
-            suggestionsList.innerHTML = results.map(suggestion => `
+            // Date queries list the first few concerts before any artist matches
+            const concertItems = concerts.slice(0, 5).map(concert => `
                 <div class="suggestion-item">
-                    <span class="suggestion-text">${suggestion.text}</span>
-                    <span class="suggestion-type">${suggestion.type}</span>
+                    <span class="suggestion-text">${escapeHTML(concert.artistName)} - ${escapeHTML(concert.location)}</span>
+                    <span class="suggestion-type">${escapeHTML(concert.displayDate)}</span>
                 </div>
             `).join('');
+            // One suggestion per artist, showing its leading match and how many more there are
+            suggestionsList.innerHTML = concertItems + groups.map(group => {
+                const best = group.matches[0];
+                const more = group.matchCount > 1 ? ` +${group.matchCount - 1}` : '';
+                return `
+                <div class="suggestion-item">
+                    <span class="suggestion-text">${highlight(best.text, best.highlights)}</span>
+                    <span class="suggestion-type">${best.type}${more}</span>
+                </div>
+            `;
+            }).join('');
         })
         .catch(error => {
             console.error('Failed to fetch suggestions:', error);
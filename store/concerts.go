@@ -0,0 +1,118 @@
+package store
+
+import (
+	"log"
+	"sort"
+
+	"groupie/models"
+	"groupie/utils"
+)
+
+// buildArtistConcerts turns the artist's upstream relation, raw location
+// names mapped to "DD-MM-YYYY" dates, into concerts in date order.
+func buildArtistConcerts(artist models.Artist, relation models.Relation) []models.Concert {
+	var concerts []models.Concert
+	for location, dates := range relation.DatesLocations {
+		place, _ := utils.ResolvePlace(utils.FormatLocation(location))
+		for _, date := range dates {
+			t, err := utils.ParseDate(date)
+			if err != nil {
+				log.Printf("Skipping concert of %s in %s: unparsable date %q", artist.Name, place.Location, date)
+				continue
+			}
+			concerts = append(concerts, models.Concert{
+				ArtistId:   artist.ID,
+				ArtistName: artist.Name,
+				Place:      place,
+				Date:       t,
+			})
+		}
+	}
+	sortConcerts(concerts)
+	return concerts
+}
+
+// sortConcerts orders concerts by date, then artist, then location.
+func sortConcerts(concerts []models.Concert) {
+	sort.Slice(concerts, func(i, j int) bool {
+		a, b := concerts[i], concerts[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.ArtistName != b.ArtistName {
+			return a.ArtistName < b.ArtistName
+		}
+		return a.Location < b.Location
+	})
+}
+
+// describeConcerts derives the artist's display lists from its concerts.
+// Locations are listed in the order they were first played.
+func describeConcerts(artist *models.Artist) {
+	artist.LocationsList = nil
+	artist.DatesList = nil
+	artist.RelationsList = make(map[string][]string)
+	artist.LocationStatesCities = make(map[string][]string)
+
+	for _, concert := range artist.Concerts {
+		location := concert.Location
+		if _, seen := artist.RelationsList[location]; !seen {
+			artist.LocationsList = append(artist.LocationsList, location)
+			if concert.City != "" && concert.State != "" {
+				region := concert.Region()
+				artist.LocationStatesCities[region] = append(artist.LocationStatesCities[region], location)
+			}
+		}
+		artist.DatesList = append(artist.DatesList, concert.DisplayDate())
+		artist.RelationsList[location] = append(artist.RelationsList[location], concert.DisplayDate())
+	}
+}
+
+// buildConcerts flattens the concerts of every artist into one list ordered
+// by date, then artist, then location.
+func buildConcerts(artists []models.Artist) []models.Concert {
+	var concerts []models.Concert
+	for _, artist := range artists {
+		concerts = append(concerts, artist.Concerts...)
+	}
+	sortConcerts(concerts)
+	return concerts
+}
+
+// locateConcerts returns a copy of concerts with the coordinates of every
+// location geocoded so far.
+func (ds *DataStore) locateConcerts(concerts []models.Concert) []models.Concert {
+	located := make([]models.Concert, len(concerts))
+	copy(located, concerts)
+	for i := range located {
+		if entry, ok := ds.cachedCoordinates(located[i].Location); ok {
+			coords := entry.Coordinates
+			located[i].Coordinates = &coords
+		}
+	}
+	return located
+}
+
+// ConcertsBetween returns the concerts within r in date order.
+func (ds *DataStore) ConcertsBetween(r models.DateRange) []models.Concert {
+	ds.mu.RLock()
+	concerts := ds.concerts
+	ds.mu.RUnlock()
+
+	start := 0
+	if !r.From.IsZero() {
+		start = sort.Search(len(concerts), func(i int) bool {
+			return !concerts[i].Date.Before(r.From)
+		})
+	}
+	end := len(concerts)
+	if !r.To.IsZero() {
+		end = sort.Search(len(concerts), func(i int) bool {
+			return !concerts[i].Date.Before(r.To)
+		})
+	}
+	if start >= end {
+		return nil
+	}
+	return ds.locateConcerts(concerts[start:end])
+}
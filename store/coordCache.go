@@ -0,0 +1,197 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"groupie/models"
+)
+
+const coordinateCacheVersion = 1
+
+// CoordinateEntry is a cached geocoding result together with when and where
+// it was obtained.
+type CoordinateEntry struct {
+	models.Coordinates
+	FetchedAt time.Time `json:"fetchedAt"`
+	Source    string    `json:"source"`
+}
+
+// GeocodeFailure records why a location could not be geocoded and when it
+// may be tried again. Each consecutive failure doubles the wait.
+type GeocodeFailure struct {
+	Location   string    `json:"location"`
+	Reason     string    `json:"reason"`
+	Attempts   int       `json:"attempts"`
+	LastTried  time.Time `json:"lastTried"`
+	RetryAfter time.Time `json:"retryAfter"`
+}
+
+func (f *GeocodeFailure) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", f.Reason, f.RetryAfter.Format(time.RFC3339))
+}
+
+const (
+	// Locations the geocoder does not know are unlikely to appear soon, so
+	// they back off much further than transport or server errors.
+	notFoundBackoff    = time.Hour
+	notFoundMaxBackoff = 30 * 24 * time.Hour
+	errorBackoff       = time.Minute
+	errorMaxBackoff    = 6 * time.Hour
+)
+
+// retryDelay returns the wait after the given number of consecutive failures.
+func retryDelay(attempts int, err error) time.Duration {
+	base, max := errorBackoff, errorMaxBackoff
+	if errors.Is(err, ErrLocationNotFound) {
+		base, max = notFoundBackoff, notFoundMaxBackoff
+	}
+
+	delay := base
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+type coordinateCacheFile struct {
+	Version  int                        `json:"version"`
+	Entries  map[string]CoordinateEntry `json:"entries"`
+	Failures map[string]GeocodeFailure  `json:"failures,omitempty"`
+}
+
+func (ds *DataStore) cachedCoordinates(location string) (CoordinateEntry, bool) {
+	ds.CoordinateCache.mu.RLock()
+	defer ds.CoordinateCache.mu.RUnlock()
+
+	entry, exists := ds.CoordinateCache.data[location]
+	return entry, exists
+}
+
+// CachedCoordinates returns the cached coordinates of a location, stale or
+// not, without queueing a lookup for missing ones.
+func (ds *DataStore) CachedCoordinates(location string) (models.Coordinates, bool) {
+	entry, exists := ds.cachedCoordinates(location)
+	return entry.Coordinates, exists
+}
+
+func (ds *DataStore) storeCoordinates(location string, coords models.Coordinates, source string) {
+	ds.CoordinateCache.mu.Lock()
+	ds.CoordinateCache.data[location] = CoordinateEntry{
+		Coordinates: coords,
+		FetchedAt:   time.Now(),
+		Source:      source,
+	}
+	delete(ds.CoordinateCache.failures, location)
+	ds.CoordinateCache.mu.Unlock()
+}
+
+// pendingFailure returns the recorded failure for location if its retry time
+// has not passed yet.
+func (ds *DataStore) pendingFailure(location string) (*GeocodeFailure, bool) {
+	ds.CoordinateCache.mu.RLock()
+	defer ds.CoordinateCache.mu.RUnlock()
+
+	failure, exists := ds.CoordinateCache.failures[location]
+	if !exists || time.Now().After(failure.RetryAfter) {
+		return nil, false
+	}
+	return &failure, true
+}
+
+func (ds *DataStore) recordFailure(location string, err error) *GeocodeFailure {
+	ds.CoordinateCache.mu.Lock()
+	defer ds.CoordinateCache.mu.Unlock()
+
+	failure := ds.CoordinateCache.failures[location]
+	now := time.Now()
+	failure.Location = location
+	failure.Reason = err.Error()
+	failure.Attempts++
+	failure.LastTried = now
+	failure.RetryAfter = now.Add(retryDelay(failure.Attempts, err))
+	ds.CoordinateCache.failures[location] = failure
+	return &failure
+}
+
+// isStale reports whether an entry is older than CoordinateTTL and should be
+// geocoded again. A zero TTL keeps entries forever.
+func (ds *DataStore) isStale(entry CoordinateEntry) bool {
+	return ds.CoordinateTTL > 0 && time.Since(entry.FetchedAt) > ds.CoordinateTTL
+}
+
+// LoadCoordinateCache fills the coordinate cache from CoordinateCachePath.
+// A missing file is not an error.
+func (ds *DataStore) LoadCoordinateCache() error {
+	if ds.CoordinateCachePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(ds.CoordinateCachePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var file coordinateCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("decode coordinate cache %s: %w", ds.CoordinateCachePath, err)
+	}
+	if file.Version != coordinateCacheVersion {
+		return fmt.Errorf("coordinate cache %s has version %d, want %d", ds.CoordinateCachePath, file.Version, coordinateCacheVersion)
+	}
+
+	ds.CoordinateCache.mu.Lock()
+	for location, entry := range file.Entries {
+		ds.CoordinateCache.data[location] = entry
+	}
+	for location, failure := range file.Failures {
+		ds.CoordinateCache.failures[location] = failure
+	}
+	ds.CoordinateCache.mu.Unlock()
+	return nil
+}
+
+// SaveCoordinateCache writes the coordinate cache to CoordinateCachePath,
+// replacing the previous file atomically.
+func (ds *DataStore) SaveCoordinateCache() error {
+	if ds.CoordinateCachePath == "" {
+		return nil
+	}
+
+	ds.CoordinateCache.saveMu.Lock()
+	defer ds.CoordinateCache.saveMu.Unlock()
+
+	ds.CoordinateCache.mu.RLock()
+	data, err := json.MarshalIndent(coordinateCacheFile{
+		Version:  coordinateCacheVersion,
+		Entries:  ds.CoordinateCache.data,
+		Failures: ds.CoordinateCache.failures,
+	}, "", "  ")
+	ds.CoordinateCache.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("encode coordinate cache: %w", err)
+	}
+
+	path := ds.CoordinateCachePath
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create coordinate cache dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write coordinate cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace coordinate cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package store
+
+import "groupie/models"
+
+// NewFixtureSource returns a small built-in dataset in the upstream format,
+// for running the server offline.
+func NewFixtureSource() *MemorySource {
+	artists := []models.Artist{
+		{
+			ID:           1,
+			Image:        "https://groupietrackers.herokuapp.com/api/images/queen.jpeg",
+			Name:         "Queen",
+			Members:      []string{"Freddie Mercury", "Brian May", "John Daecon", "Roger Meddows-Taylor", "Mike Grose", "Barry Mitchell", "Doug Fogie"},
+			CreationDate: 1970,
+			FirstAlbum:   "14-12-1973",
+		},
+		{
+			ID:           2,
+			Image:        "https://groupietrackers.herokuapp.com/api/images/pinkfloyd.jpeg",
+			Name:         "Pink Floyd",
+			Members:      []string{"Roger Waters", "Nick Mason", "David Gilmour", "Richard Wright", "Syd Barrett"},
+			CreationDate: 1965,
+			FirstAlbum:   "05-08-1967",
+		},
+		{
+			ID:           3,
+			Image:        "https://groupietrackers.herokuapp.com/api/images/metallica.jpeg",
+			Name:         "Metallica",
+			Members:      []string{"James Hetfield", "Kirk Hammett", "Lars Ulrich", "Robert Trujillo"},
+			CreationDate: 1981,
+			FirstAlbum:   "25-07-1983",
+		},
+		{
+			ID:           4,
+			Image:        "https://groupietrackers.herokuapp.com/api/images/scorpions.jpeg",
+			Name:         "Scorpions",
+			Members:      []string{"Rudolf Schenker", "Klaus Meine", "Matthias Jabs", "Pawel Maciwoda", "Mikkey Dee"},
+			CreationDate: 1965,
+			FirstAlbum:   "01-01-1972",
+		},
+	}
+
+	relations := map[int]map[string][]string{
+		1: {
+			"north_carolina-usa":  {"30-01-2019"},
+			"georgia-usa":         {"22-08-2019"},
+			"los_angeles-usa":     {"20-08-2019"},
+			"saitama-japan":       {"26-01-2020"},
+			"osaka-japan":         {"28-01-2020"},
+			"nagoya-japan":        {"30-01-2020"},
+			"penrose-new_zealand": {"07-02-2020"},
+			"dunedin-new_zealand": {"10-02-2020"},
+		},
+		2: {
+			"london-uk":             {"05-12-2019", "06-12-2019"},
+			"paris-france":          {"12-12-2019"},
+			"berlin-germany":        {"15-12-2019"},
+			"amsterdam-netherlands": {"18-12-2019"},
+		},
+		3: {
+			"san_francisco-usa":  {"15-01-2020"},
+			"seattle-usa":        {"18-01-2020"},
+			"dallas-usa":         {"22-01-2020"},
+			"mexico_city-mexico": {"28-01-2020"},
+			"sao_paulo-brazil":   {"02-02-2020"},
+		},
+		4: {
+			"hamburg-germany":     {"10-11-2019"},
+			"munich-germany":      {"12-11-2019"},
+			"zurich-switzerland":  {"15-11-2019"},
+			"sydney-australia":    {"01-03-2020"},
+			"melbourne-australia": {"03-03-2020"},
+		},
+	}
+
+	source := &MemorySource{
+		ArtistList:  artists,
+		RelationMap: make(map[int]models.Relation),
+	}
+	for id, datesLocations := range relations {
+		source.RelationMap[id] = models.Relation{ID: id, DatesLocations: datesLocations}
+	}
+	return source
+}
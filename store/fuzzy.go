@@ -0,0 +1,110 @@
+package store
+
+import "strings"
+
+// diacriticFolds maps accented Latin letters to their unaccented base so
+// that "beyonce" matches "Beyoncé". Input is lowercased before folding.
+var diacriticFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+func foldDiacritics(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if folded, ok := diacriticFolds[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// maxEdits is the edit distance tolerated for a query token: none for short
+// tokens, where a typo is indistinguishable from a different word.
+func maxEdits(token string) int {
+	switch n := len([]rune(token)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// trigrams returns the padded trigrams of token, so that "abc" yields
+// "$$a", "$ab", "abc", "bc$".
+func trigrams(token string) []string {
+	runes := []rune("$$" + token + "$")
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// fuzzyTerms returns the indexed tokens within maxEdits of token, with their
+// distance. Candidates are narrowed by shared trigrams before computing the
+// edit distance: k edits can destroy at most 3k of a token's trigrams.
+func (idx *SearchIndex) fuzzyTerms(token string) map[string]int {
+	k := maxEdits(token)
+	if k == 0 {
+		return nil
+	}
+
+	grams := trigrams(token)
+	shared := make(map[int]int)
+	for _, gram := range grams {
+		for _, term := range idx.trigrams[gram] {
+			shared[term]++
+		}
+	}
+
+	needed := len(grams) - 3*k
+	if needed < 1 {
+		needed = 1
+	}
+
+	terms := make(map[string]int)
+	for term, count := range shared {
+		if count < needed {
+			continue
+		}
+		candidate := idx.vocab[term]
+		if d := levenshtein(token, candidate); d > 0 && d <= k {
+			terms[candidate] = d
+		}
+	}
+	return terms
+}
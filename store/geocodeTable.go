@@ -0,0 +1,148 @@
+package store
+
+import "groupie/models"
+
+// knownLocations holds approximate coordinates for concert locations that
+// appear in the Groupie Trackers dataset, keyed by utils.FormatLocation output.
+var knownLocations = map[string]models.Coordinates{
+	// North America
+	"Anaheim, Usa":             {Lat: 33.8366, Lon: -117.9143},
+	"Atlanta, Usa":             {Lat: 33.7490, Lon: -84.3880},
+	"Berwyn, Usa":              {Lat: 41.8506, Lon: -87.7937},
+	"Boston, Usa":              {Lat: 42.3601, Lon: -71.0589},
+	"Brooklyn, Usa":            {Lat: 40.6782, Lon: -73.9442},
+	"California, Usa":          {Lat: 36.7783, Lon: -119.4179},
+	"Charlotte, Usa":           {Lat: 35.2271, Lon: -80.8431},
+	"Chicago, Usa":             {Lat: 41.8781, Lon: -87.6298},
+	"Cincinnati, Usa":          {Lat: 39.1031, Lon: -84.5120},
+	"Cleveland, Usa":           {Lat: 41.4993, Lon: -81.6944},
+	"Columbia, Usa":            {Lat: 34.0007, Lon: -81.0348},
+	"Dallas, Usa":              {Lat: 32.7767, Lon: -96.7970},
+	"Del Mar, Usa":             {Lat: 32.9595, Lon: -117.2653},
+	"Detroit, Usa":             {Lat: 42.3314, Lon: -83.0458},
+	"Georgia, Usa":             {Lat: 32.1656, Lon: -82.9001},
+	"Grand Rapids, Usa":        {Lat: 42.9634, Lon: -85.6681},
+	"Hershey, Usa":             {Lat: 40.2859, Lon: -76.6502},
+	"Houston, Usa":             {Lat: 29.7604, Lon: -95.3698},
+	"Indianapolis, Usa":        {Lat: 39.7684, Lon: -86.1581},
+	"Inglewood, Usa":           {Lat: 33.9617, Lon: -118.3531},
+	"Kansas City, Usa":         {Lat: 39.0997, Lon: -94.5786},
+	"Las Vegas, Usa":           {Lat: 36.1699, Lon: -115.1398},
+	"Los Angeles, Usa":         {Lat: 34.0522, Lon: -118.2437},
+	"Madison, Usa":             {Lat: 43.0731, Lon: -89.4012},
+	"Nevada, Usa":              {Lat: 38.8026, Lon: -116.4194},
+	"New Orleans, Usa":         {Lat: 29.9511, Lon: -90.0715},
+	"New York, Usa":            {Lat: 40.7128, Lon: -74.0060},
+	"Newark, Usa":              {Lat: 40.7357, Lon: -74.1724},
+	"North Carolina, Usa":      {Lat: 35.7596, Lon: -79.0193},
+	"Oakland, Usa":             {Lat: 37.8044, Lon: -122.2712},
+	"Omaha, Usa":               {Lat: 41.2565, Lon: -95.9345},
+	"Philadelphia, Usa":        {Lat: 39.9526, Lon: -75.1652},
+	"Pico Rivera, Usa":         {Lat: 33.9831, Lon: -118.0967},
+	"Pittsburgh, Usa":          {Lat: 40.4406, Lon: -79.9959},
+	"Rosemont, Usa":            {Lat: 41.9953, Lon: -87.8845},
+	"San Francisco, Usa":       {Lat: 37.7749, Lon: -122.4194},
+	"Seattle, Usa":             {Lat: 47.6062, Lon: -122.3321},
+	"South Carolina, Usa":      {Lat: 33.8361, Lon: -81.1637},
+	"St Louis, Usa":            {Lat: 38.6270, Lon: -90.1994},
+	"Uniondale, Usa":           {Lat: 40.7004, Lon: -73.5929},
+	"Washington, Usa":          {Lat: 47.7511, Lon: -120.7401},
+	"Montreal, Canada":         {Lat: 45.5017, Lon: -73.5673},
+	"Toronto, Canada":          {Lat: 43.6532, Lon: -79.3832},
+	"Vancouver, Canada":        {Lat: 49.2827, Lon: -123.1207},
+	"Guadalajara, Mexico":      {Lat: 20.6597, Lon: -103.3496},
+	"Mexico City, Mexico":      {Lat: 19.4326, Lon: -99.1332},
+	"Monterrey, Mexico":        {Lat: 25.6866, Lon: -100.3161},
+	"Playa Del Carmen, Mexico": {Lat: 20.6296, Lon: -87.0739},
+
+	// South America
+	"Bogota, Colombia":        {Lat: 4.7110, Lon: -74.0721},
+	"Buenos Aires, Argentina": {Lat: -34.6037, Lon: -58.3816},
+	"La Plata, Argentina":     {Lat: -34.9215, Lon: -57.9545},
+	"Lima, Peru":              {Lat: -12.0464, Lon: -77.0428},
+	"Rio De Janeiro, Brazil":  {Lat: -22.9068, Lon: -43.1729},
+	"San Isidro, Argentina":   {Lat: -34.4708, Lon: -58.5286},
+	"Santiago, Chile":         {Lat: -33.4489, Lon: -70.6693},
+	"Sao Paulo, Brazil":       {Lat: -23.5505, Lon: -46.6333},
+
+	// Europe
+	"Aarhus, Denmark":          {Lat: 56.1629, Lon: 10.2039},
+	"Amsterdam, Netherlands":   {Lat: 52.3676, Lon: 4.9041},
+	"Athens, Greece":           {Lat: 37.9838, Lon: 23.7275},
+	"Barcelona, Spain":         {Lat: 41.3851, Lon: 2.1734},
+	"Berlin, Germany":          {Lat: 52.5200, Lon: 13.4050},
+	"Birmingham, Uk":           {Lat: 52.4862, Lon: -1.8904},
+	"Bratislava, Slovakia":     {Lat: 48.1486, Lon: 17.1077},
+	"Brussels, Belgium":        {Lat: 50.8503, Lon: 4.3517},
+	"Budapest, Hungary":        {Lat: 47.4979, Lon: 19.0402},
+	"Copenhagen, Denmark":      {Lat: 55.6761, Lon: 12.5683},
+	"Dublin, Ireland":          {Lat: 53.3498, Lon: -6.2603},
+	"Dusseldorf, Germany":      {Lat: 51.2277, Lon: 6.7735},
+	"Frankfurt, Germany":       {Lat: 50.1109, Lon: 8.6821},
+	"Glasgow, Uk":              {Lat: 55.8642, Lon: -4.2518},
+	"Hamburg, Germany":         {Lat: 53.5511, Lon: 9.9937},
+	"Helsinki, Finland":        {Lat: 60.1699, Lon: 24.9384},
+	"Istanbul, Turkey":         {Lat: 41.0082, Lon: 28.9784},
+	"Kiev, Ukraine":            {Lat: 50.4501, Lon: 30.5234},
+	"Krakow, Poland":           {Lat: 50.0647, Lon: 19.9450},
+	"Lausanne, Switzerland":    {Lat: 46.5197, Lon: 6.6323},
+	"Lisbon, Portugal":         {Lat: 38.7223, Lon: -9.1393},
+	"London, Uk":               {Lat: 51.5074, Lon: -0.1278},
+	"Lyon, France":             {Lat: 45.7640, Lon: 4.8357},
+	"Madrid, Spain":            {Lat: 40.4168, Lon: -3.7038},
+	"Manchester, Uk":           {Lat: 53.4808, Lon: -2.2426},
+	"Milan, Italy":             {Lat: 45.4642, Lon: 9.1900},
+	"Minsk, Belarus":           {Lat: 53.9006, Lon: 27.5590},
+	"Moscow, Russia":           {Lat: 55.7558, Lon: 37.6173},
+	"Munich, Germany":          {Lat: 48.1351, Lon: 11.5820},
+	"Oslo, Norway":             {Lat: 59.9139, Lon: 10.7522},
+	"Paris, France":            {Lat: 48.8566, Lon: 2.3522},
+	"Prague, Czechia":          {Lat: 50.0755, Lon: 14.4378},
+	"Rome, Italy":              {Lat: 41.9028, Lon: 12.4964},
+	"Saint Petersburg, Russia": {Lat: 59.9311, Lon: 30.3609},
+	"Stockholm, Sweden":        {Lat: 59.3293, Lon: 18.0686},
+	"Vienna, Austria":          {Lat: 48.2082, Lon: 16.3738},
+	"Warsaw, Poland":           {Lat: 52.2297, Lon: 21.0122},
+	"Zaragoza, Spain":          {Lat: 41.6488, Lon: -0.8891},
+	"Zurich, Switzerland":      {Lat: 47.3769, Lon: 8.5417},
+
+	// Asia and Middle East
+	"Abu Dhabi, United Arab Emirates": {Lat: 24.4539, Lon: 54.3773},
+	"Bangkok, Thailand":               {Lat: 13.7563, Lon: 100.5018},
+	"Beijing, China":                  {Lat: 39.9042, Lon: 116.4074},
+	"Doha, Qatar":                     {Lat: 25.2854, Lon: 51.5310},
+	"Dubai, United Arab Emirates":     {Lat: 25.2048, Lon: 55.2708},
+	"Hong Kong, China":                {Lat: 22.3193, Lon: 114.1694},
+	"Jakarta, Indonesia":              {Lat: -6.2088, Lon: 106.8456},
+	"Manila, Philippines":             {Lat: 14.5995, Lon: 120.9842},
+	"Mumbai, India":                   {Lat: 19.0760, Lon: 72.8777},
+	"Nagoya, Japan":                   {Lat: 35.1815, Lon: 136.9066},
+	"Osaka, Japan":                    {Lat: 34.6937, Lon: 135.5023},
+	"Saitama, Japan":                  {Lat: 35.8617, Lon: 139.6455},
+	"Seoul, South Korea":              {Lat: 37.5665, Lon: 126.9780},
+	"Shanghai, China":                 {Lat: 31.2304, Lon: 121.4737},
+	"Singapore, Singapore":            {Lat: 1.3521, Lon: 103.8198},
+	"Taipei, Taiwan":                  {Lat: 25.0330, Lon: 121.5654},
+	"Tokyo, Japan":                    {Lat: 35.6762, Lon: 139.6503},
+	"Yogyakarta, Indonesia":           {Lat: -7.7956, Lon: 110.3695},
+
+	// Africa
+	"Cape Town, South Africa":    {Lat: -33.9249, Lon: 18.4241},
+	"Johannesburg, South Africa": {Lat: -26.2041, Lon: 28.0473},
+
+	// Oceania
+	"Auckland, New Zealand":      {Lat: -36.8485, Lon: 174.7633},
+	"Brisbane, Australia":        {Lat: -27.4698, Lon: 153.0251},
+	"Burswood, Australia":        {Lat: -31.9610, Lon: 115.8947},
+	"Dunedin, New Zealand":       {Lat: -45.8788, Lon: 170.5028},
+	"Melbourne, Australia":       {Lat: -37.8136, Lon: 144.9631},
+	"New South Wales, Australia": {Lat: -31.2532, Lon: 146.9211},
+	"Noumea, New Caledonia":      {Lat: -22.2758, Lon: 166.4580},
+	"Papeete, French Polynesia":  {Lat: -17.5516, Lon: -149.5585},
+	"Penrose, New Zealand":       {Lat: -36.9105, Lon: 174.8155},
+	"Queensland, Australia":      {Lat: -20.9176, Lon: 142.7028},
+	"Sydney, Australia":          {Lat: -33.8688, Lon: 151.2093},
+	"Victoria, Australia":        {Lat: -37.4713, Lon: 144.7852},
+	"West Melbourne, Australia":  {Lat: -37.8106, Lon: 144.9436},
+	"Wellington, New Zealand":    {Lat: -41.2865, Lon: 174.7762},
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"groupie/models"
+)
+
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+
+// ErrLocationNotFound is returned by a Geocoder that answered but does not
+// know the location, as opposed to a transport or server failure.
+var ErrLocationNotFound = errors.New("no coordinates found for location")
+
+// Geocoder resolves a formatted location such as "Los Angeles, Usa" to
+// coordinates. Name is recorded as the source of cached entries.
+type Geocoder interface {
+	Name() string
+	Geocode(location string) (models.Coordinates, error)
+}
+
+// NominatimGeocoder queries a Nominatim server. The public instance allows at
+// most one request per second, which callers are responsible for honouring.
+type NominatimGeocoder struct {
+	BaseURL   string
+	UserAgent string
+	client    *http.Client
+}
+
+func NewNominatimGeocoder(baseURL string) *NominatimGeocoder {
+	if baseURL == "" {
+		baseURL = DefaultNominatimURL
+	}
+	return &NominatimGeocoder{
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		UserAgent: "GroupieTracker/1.0 (https://github.com/groupie-tracker)",
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g *NominatimGeocoder) Name() string {
+	return "nominatim"
+}
+
+func (g *NominatimGeocoder) Geocode(location string) (models.Coordinates, error) {
+	encodedLocation := url.QueryEscape(location)
+	apiURL := fmt.Sprintf("%s/search?format=json&q=%s&limit=1", g.BaseURL, encodedLocation)
+
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+	req.Header.Set("User-Agent", g.UserAgent)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Referer", "https://github.com/groupie-tracker")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.Coordinates{}, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+	}
+
+	var nominatimResp []models.NominatimResponse
+	if err := json.NewDecoder(resp.Body).Decode(&nominatimResp); err != nil {
+		return models.Coordinates{}, err
+	}
+
+	if len(nominatimResp) == 0 {
+		return models.Coordinates{}, ErrLocationNotFound
+	}
+
+	lat, _ := strconv.ParseFloat(nominatimResp[0].Lat, 64)
+	lon, _ := strconv.ParseFloat(nominatimResp[0].Lon, 64)
+
+	return models.Coordinates{
+		Lat:     lat,
+		Lon:     lon,
+		Address: location,
+	}, nil
+}
+
+// StaticGeocoder answers from a fixed table and never touches the network.
+type StaticGeocoder struct {
+	Table map[string]models.Coordinates
+}
+
+// NewStaticGeocoder returns a StaticGeocoder over the bundled table of known
+// concert cities.
+func NewStaticGeocoder() *StaticGeocoder {
+	return &StaticGeocoder{Table: knownLocations}
+}
+
+func (g *StaticGeocoder) Name() string {
+	return "static"
+}
+
+func (g *StaticGeocoder) Geocode(location string) (models.Coordinates, error) {
+	coords, ok := g.Table[location]
+	if !ok {
+		return models.Coordinates{}, ErrLocationNotFound
+	}
+	coords.Address = location
+	return coords, nil
+}
+
+// FakeGeocoder derives stable pseudo-coordinates from the location name, for
+// tests and offline development. Locations listed in Unknown are not found.
+type FakeGeocoder struct {
+	Unknown map[string]bool
+}
+
+func (g *FakeGeocoder) Name() string {
+	return "fake"
+}
+
+func (g *FakeGeocoder) Geocode(location string) (models.Coordinates, error) {
+	if g.Unknown[location] {
+		return models.Coordinates{}, ErrLocationNotFound
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(location))
+	sum := h.Sum64()
+
+	return models.Coordinates{
+		Lat:     float64(sum%13000)/100 - 60,
+		Lon:     float64((sum/13000)%36000)/100 - 180,
+		Address: location,
+	}, nil
+}
+
+// ChainGeocoder tries each geocoder in order and returns the first answer.
+// It is typically used to consult the static table before a network service.
+type ChainGeocoder []Geocoder
+
+func (c ChainGeocoder) Name() string {
+	names := make([]string, len(c))
+	for i, g := range c {
+		names[i] = g.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+func (c ChainGeocoder) Geocode(location string) (models.Coordinates, error) {
+	coords, _, err := c.GeocodeSource(location)
+	return coords, err
+}
+
+// GeocodeSource is Geocode that also returns the name of the geocoder that
+// answered, for recording as the source of the cached entry.
+func (c ChainGeocoder) GeocodeSource(location string) (models.Coordinates, string, error) {
+	err := ErrLocationNotFound
+	for _, g := range c {
+		var coords models.Coordinates
+		var source string
+		coords, source, err = geocodeSource(g, location)
+		if err == nil {
+			return coords, source, nil
+		}
+	}
+	return models.Coordinates{}, "", err
+}
+
+// geocodeSource geocodes location with g and names the geocoder that
+// answered: g itself unless it reports another, as a chain does.
+func geocodeSource(g Geocoder, location string) (models.Coordinates, string, error) {
+	if sg, ok := g.(interface {
+		GeocodeSource(string) (models.Coordinates, string, error)
+	}); ok {
+		return sg.GeocodeSource(location)
+	}
+	coords, err := g.Geocode(location)
+	return coords, g.Name(), err
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"unicode"
+
+	"groupie/models"
+)
+
+// highlightSpans marks the words of text whose normalized form is one of the
+// matched index tokens. matched maps each token to the number of its leading
+// runes the query covered, so a prefix query only marks the prefix. Offsets
+// count runes of text, not bytes.
+func highlightSpans(text string, matched map[string]int) []models.MatchRange {
+	if len(matched) == 0 {
+		return nil
+	}
+
+	var spans []models.MatchRange
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		word := runes[start:end]
+		if length, ok := matched[normalize(string(word))]; ok {
+			spans = append(spans, models.MatchRange{Start: start, End: start + originalRunes(word, length)})
+		}
+		start = end
+	}
+	return spans
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// originalRunes converts a length in normalized runes back to a length in
+// runes of word, since folding can expand a letter such as "ß" into "ss".
+func originalRunes(word []rune, normalizedLength int) int {
+	covered := 0
+	for i, r := range word {
+		if covered >= normalizedLength {
+			return i
+		}
+		covered += len([]rune(normalize(string(r))))
+	}
+	return len(word)
+}
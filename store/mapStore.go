@@ -1,109 +1,162 @@
 package store
 
 import (
-	"encoding/json"
-	"fmt"
 	"log"
-	"net/http"
-	"net/url"
-	"strconv"
-	"time"
 
 	"groupie/models"
 )
 
+// loadCoordinatesInBackground geocodes every unique location that is not
+// cached yet or whose cached entry is stale. Only one loader runs at a time;
+// a reload that happens while one is running is picked up by on-demand
+// lookups or the next refresh.
 func (ds *DataStore) loadCoordinatesInBackground() {
-	ds.CoordinateCache.data = make(map[string]models.Coordinates)
+	if !ds.coordLoading.CompareAndSwap(false, true) {
+		return
+	}
+	locations := ds.GetUniqueLocations()
 
 	go func() {
-		// Rate limit: Nominatim API requires max 1 request per second; use 2s for safety
-		rateLimiter := time.NewTicker(2 * time.Second)
-		defer rateLimiter.Stop()
-
-		for _, location := range ds.UniqueLocations {
-			<-rateLimiter.C
+		defer ds.coordLoading.Store(false)
 
-			ds.CoordinateCache.mu.RLock()
-			_, exists := ds.CoordinateCache.data[location]
-			ds.CoordinateCache.mu.RUnlock()
-
-			if exists {
+		attempted := 0
+		for _, location := range locations {
+			if entry, exists := ds.cachedCoordinates(location); exists && !ds.isStale(entry) {
+				continue
+			}
+			if _, pending := ds.pendingFailure(location); pending {
 				continue
 			}
 
-			coords, err := ds.fetchCoordinatesFromAPI(location)
-			if err != nil {
+			attempted++
+			if _, err := ds.resolveCoordinates(location); err != nil {
 				log.Printf("Failed to fetch coordinates for %s: %v", location, err)
-				continue
 			}
 
-			ds.CoordinateCache.mu.Lock()
-			ds.CoordinateCache.data[location] = coords
-			ds.CoordinateCache.mu.Unlock()
+			// Persist progress regularly so a restart does not lose a long run
+			if attempted%20 == 0 {
+				if err := ds.SaveCoordinateCache(); err != nil {
+					log.Printf("Failed to save coordinate cache: %v", err)
+				}
+			}
+		}
+		if attempted > 0 {
+			if err := ds.SaveCoordinateCache(); err != nil {
+				log.Printf("Failed to save coordinate cache: %v", err)
+			}
 		}
 		log.Println("Background coordinate loading completed")
 	}()
 }
 
-func (ds *DataStore) GetLocationCoordinates(location string) (models.Coordinates, error) {
-	ds.CoordinateCache.mu.RLock()
-	coords, exists := ds.CoordinateCache.data[location]
-	ds.CoordinateCache.mu.RUnlock()
-
-	if exists {
-		return coords, nil
+// CoordinateStatus reports what is known about a location without waiting
+// on the geocoder. Locations that are neither cached nor in a failure backoff
+// are queued for an on-demand lookup and reported pending. The queue has no
+// priority over the background loader; both wait their turn at the same
+// rate limiter.
+func (ds *DataStore) CoordinateStatus(location string) models.LocationStatus {
+	status := models.LocationStatus{Location: location}
+
+	if entry, exists := ds.cachedCoordinates(location); exists {
+		coords := entry.Coordinates
+		status.Status = models.StatusResolved
+		status.Coordinates = &coords
+		return status
 	}
 
-	coords, err := ds.fetchCoordinatesFromAPI(location)
-	if err != nil {
-		return models.Coordinates{}, fmt.Errorf("failed to fetch coordinates: %v", err)
+	if failure, pending := ds.pendingFailure(location); pending {
+		status.Status = models.StatusFailed
+		status.Reason = failure.Reason
+		status.RetryAfter = &failure.RetryAfter
+		return status
 	}
 
-	ds.CoordinateCache.mu.Lock()
-	ds.CoordinateCache.data[location] = coords
-	ds.CoordinateCache.mu.Unlock()
-
-	return coords, nil
+	ds.enqueueLookup(location)
+	status.Status = models.StatusPending
+	return status
 }
 
-func (ds *DataStore) fetchCoordinatesFromAPI(location string) (models.Coordinates, error) {
-	encodedLocation := url.QueryEscape(location)
-	apiURL := fmt.Sprintf("https://nominatim.openstreetmap.org/search?format=json&q=%s&limit=1", encodedLocation)
+func (ds *DataStore) enqueueLookup(location string) {
+	ds.lookupQueue.start.Do(func() { go ds.runLookupQueue() })
 
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return models.Coordinates{}, err
+	ds.lookupQueue.mu.Lock()
+	defer ds.lookupQueue.mu.Unlock()
+	if ds.lookupQueue.queued[location] {
+		return
 	}
-	req.Header.Set("User-Agent", "GroupieTracker/1.0 (https://github.com/groupie-tracker)")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Referer", "https://github.com/groupie-tracker")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return models.Coordinates{}, err
+	select {
+	case ds.lookupQueue.ch <- location:
+		ds.lookupQueue.queued[location] = true
+	default:
+		// Queue is full; the location will be queued again on the next poll
 	}
-	defer resp.Body.Close()
+}
+
+// runLookupQueue resolves queued on-demand lookups one at a time. It shares
+// the geocoder, and therefore its rate limiter, with the background loader.
+func (ds *DataStore) runLookupQueue() {
+	for location := range ds.lookupQueue.ch {
+		_, cached := ds.cachedCoordinates(location)
+		_, pending := ds.pendingFailure(location)
+		if !cached && !pending {
+			if _, err := ds.resolveCoordinates(location); err != nil {
+				log.Printf("Failed to fetch coordinates for %s: %v", location, err)
+			}
+		}
+
+		ds.lookupQueue.mu.Lock()
+		delete(ds.lookupQueue.queued, location)
+		idle := len(ds.lookupQueue.ch) == 0
+		ds.lookupQueue.mu.Unlock()
+
+		if idle {
+			if err := ds.SaveCoordinateCache(); err != nil {
+				log.Printf("Failed to save coordinate cache: %v", err)
+			}
+		}
+	}
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return models.Coordinates{}, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+// coordinateCall is an in-flight geocoding request that concurrent lookups
+// of the same location wait on instead of issuing their own.
+type coordinateCall struct {
+	done   chan struct{}
+	coords models.Coordinates
+	err    error
+}
+
+// resolveCoordinates geocodes location and caches the result. Concurrent calls
+// for the same location share a single upstream request. Failures are cached
+// too and returned as *GeocodeFailure without calling upstream until their
+// retry time has passed.
+func (ds *DataStore) resolveCoordinates(location string) (models.Coordinates, error) {
+	if failure, pending := ds.pendingFailure(location); pending {
+		return models.Coordinates{}, failure
 	}
 
-	var nominatimResp []models.NominatimResponse
-	if err := json.NewDecoder(resp.Body).Decode(&nominatimResp); err != nil {
-		return models.Coordinates{}, err
+	ds.inflightMu.Lock()
+	if call, ok := ds.inflight[location]; ok {
+		ds.inflightMu.Unlock()
+		<-call.done
+		return call.coords, call.err
 	}
+	call := &coordinateCall{done: make(chan struct{})}
+	ds.inflight[location] = call
+	ds.inflightMu.Unlock()
 
-	if len(nominatimResp) == 0 {
-		return models.Coordinates{}, fmt.Errorf("no coordinates found for location")
+	coords, source, err := geocodeSource(ds.Geocoder, location)
+	if err != nil {
+		call.err = ds.recordFailure(location, err)
+	} else {
+		call.coords = coords
+		ds.storeCoordinates(location, coords, source)
 	}
 
-	lat, _ := strconv.ParseFloat(nominatimResp[0].Lat, 64)
-	lon, _ := strconv.ParseFloat(nominatimResp[0].Lon, 64)
+	ds.inflightMu.Lock()
+	delete(ds.inflight, location)
+	ds.inflightMu.Unlock()
+	close(call.done)
 
-	return models.Coordinates{
-		Lat:     lat,
-		Lon:     lon,
-		Address: location,
-	}, nil
+	return call.coords, call.err
 }
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"groupie/models"
+)
+
+// NominatimInterval keeps requests to the public Nominatim instance well
+// under its limit of one request per second.
+const NominatimInterval = 2 * time.Second
+
+// RateLimitedGeocoder spaces calls to the wrapped Geocoder at least interval
+// apart. Callers queue for the next free slot in arrival order, so a single
+// instance shared by every lookup path enforces one global rate.
+type RateLimitedGeocoder struct {
+	Geocoder
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+func NewRateLimitedGeocoder(g Geocoder, interval time.Duration) *RateLimitedGeocoder {
+	return &RateLimitedGeocoder{Geocoder: g, interval: interval}
+}
+
+func (g *RateLimitedGeocoder) Geocode(location string) (models.Coordinates, error) {
+	g.wait()
+	return g.Geocoder.Geocode(location)
+}
+
+// GeocodeSource names the wrapped geocoder that answered, as geocodeSource
+// does.
+func (g *RateLimitedGeocoder) GeocodeSource(location string) (models.Coordinates, string, error) {
+	g.wait()
+	return geocodeSource(g.Geocoder, location)
+}
+
+func (g *RateLimitedGeocoder) wait() {
+	g.mu.Lock()
+	now := time.Now()
+	slot := g.next
+	if slot.Before(now) {
+		slot = now
+	}
+	g.next = slot.Add(g.interval)
+	g.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
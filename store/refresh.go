@@ -0,0 +1,97 @@
+package store
+
+import (
+	"log"
+	"time"
+)
+
+// RefreshStatus describes the outcome of the most recent load from the source.
+// Until the first refresh, a warm start reports the snapshot it loaded, with
+// LastSuccess set to when the snapshot was saved.
+type RefreshStatus struct {
+	LastAttempt time.Time `json:"lastAttempt"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	Origin      string    `json:"origin,omitempty"`
+	LastError   string    `json:"lastError,omitempty"`
+	Duration    string    `json:"duration"`
+	Artists     int       `json:"artists"`
+	Refreshes   int       `json:"refreshes"`
+	Failures    int       `json:"failures"`
+}
+
+// Refresh fetches a complete new dataset from the source and swaps it in only
+// if every request succeeded. On failure the current data is kept untouched.
+// Concurrent calls are serialized.
+func (ds *DataStore) Refresh() error {
+	ds.refreshMu.Lock()
+	defer ds.refreshMu.Unlock()
+
+	start := time.Now()
+	artists, err := ds.fetchArtists()
+	if err == nil {
+		// Swap the data in before reporting it, so the status never
+		// describes data that is not live yet
+		ds.setArtists(artists)
+	}
+
+	ds.mu.Lock()
+	ds.status.LastAttempt = start
+	ds.status.Duration = time.Since(start).Round(time.Millisecond).String()
+	if err != nil {
+		ds.status.LastError = err.Error()
+		ds.status.Failures++
+	} else {
+		ds.status.LastSuccess = start
+		ds.status.LastError = ""
+		ds.status.Origin = "source"
+		ds.status.Artists = len(artists)
+		ds.status.Refreshes++
+	}
+	ds.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if ds.SnapshotPath != "" {
+		if err := ds.SaveSnapshot(ds.SnapshotPath); err != nil {
+			log.Printf("Failed to save snapshot: %v", err)
+		}
+	}
+	return nil
+}
+
+// StartRefresher calls Refresh every interval until the returned stop
+// function is called. A non-positive interval disables periodic refresh.
+func (ds *DataStore) StartRefresher(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := ds.Refresh(); err != nil {
+					log.Printf("Periodic refresh failed, keeping current data: %v", err)
+				} else {
+					log.Printf("Periodic refresh completed")
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (ds *DataStore) GetRefreshStatus() RefreshStatus {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	return ds.status
+}
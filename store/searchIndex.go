@@ -0,0 +1,372 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"groupie/models"
+	"groupie/utils"
+)
+
+// Search result types in their default display order.
+const (
+	TypeArtist       = "artist/band"
+	TypeMember       = "member"
+	TypeLocation     = "location"
+	TypeCreationDate = "creation date"
+	TypeFirstAlbum   = "first album"
+)
+
+var typeOrder = map[string]int{
+	TypeArtist:       0,
+	TypeMember:       1,
+	TypeLocation:     2,
+	TypeCreationDate: 3,
+	TypeFirstAlbum:   4,
+}
+
+// fieldWeight ranks a match on an artist name above the same match on a
+// member, and so on down to dates.
+var fieldWeight = map[string]float64{
+	TypeArtist:       4,
+	TypeMember:       3,
+	TypeLocation:     2,
+	TypeFirstAlbum:   1.5,
+	TypeCreationDate: 1,
+}
+
+// searchDoc is one searchable value, such as a single member of one artist,
+// with the result it produces when matched.
+type searchDoc struct {
+	result models.SearchResult
+	text   string
+	tokens []string
+}
+
+// trieNode indexes token prefixes. Every node holds the documents of all
+// tokens that pass through it, so a prefix lookup is a single walk.
+type trieNode struct {
+	children map[rune]*trieNode
+	prefix   []int
+	exact    []int
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[rune]*trieNode)}
+}
+
+func (n *trieNode) insert(token string, doc int) {
+	node := n
+	for _, r := range token {
+		child, ok := node.children[r]
+		if !ok {
+			child = newTrieNode()
+			node.children[r] = child
+		}
+		node = child
+		node.prefix = appendUnique(node.prefix, doc)
+	}
+	node.exact = appendUnique(node.exact, doc)
+}
+
+func (n *trieNode) find(prefix string) *trieNode {
+	node := n
+	for _, r := range prefix {
+		child, ok := node.children[r]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+	return node
+}
+
+// appendUnique relies on documents being inserted in increasing order.
+func appendUnique(ids []int, id int) []int {
+	if len(ids) > 0 && ids[len(ids)-1] == id {
+		return ids
+	}
+	return append(ids, id)
+}
+
+// SearchIndex is an immutable inverted index over artist names, members,
+// locations, creation dates and first albums, with one prefix trie per field.
+type SearchIndex struct {
+	docs  []searchDoc
+	tries map[string]*trieNode
+
+	// vocab lists every distinct token; trigrams maps each trigram to the
+	// vocab entries containing it, for typo-tolerant lookups.
+	vocab    []string
+	vocabIDs map[string]int
+	trigrams map[string][]int
+}
+
+// normalize lowercases s and strips diacritics.
+func normalize(s string) string {
+	return foldDiacritics(strings.ToLower(s))
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(normalize(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func NewSearchIndex(artists []models.Artist) *SearchIndex {
+	idx := &SearchIndex{
+		tries:    make(map[string]*trieNode),
+		vocabIDs: make(map[string]int),
+		trigrams: make(map[string][]int),
+	}
+	for field := range typeOrder {
+		idx.tries[field] = newTrieNode()
+	}
+
+	for _, artist := range artists {
+		idx.add(artist.Name, models.SearchResult{
+			Text:        artist.Name,
+			Type:        TypeArtist,
+			ArtistName:  artist.Name,
+			Description: fmt.Sprintf("Band formed in %d", artist.CreationDate),
+			ArtistId:    artist.ID,
+		})
+
+		for _, member := range artist.Members {
+			idx.add(member, models.SearchResult{
+				Text:        member,
+				Type:        TypeMember,
+				ArtistName:  artist.Name,
+				Description: fmt.Sprintf("Member of %s", artist.Name),
+				ArtistId:    artist.ID,
+			})
+		}
+
+		for _, location := range artist.LocationsList {
+			idx.add(location, models.SearchResult{
+				Text:        location,
+				Type:        TypeLocation,
+				ArtistName:  artist.Name,
+				Description: fmt.Sprintf("Concert location for %s", artist.Name),
+				ArtistId:    artist.ID,
+			})
+		}
+
+		for _, region := range artistRegions(artist) {
+			idx.add(region, models.SearchResult{
+				Text:        region,
+				Type:        TypeLocation,
+				ArtistName:  artist.Name,
+				Description: fmt.Sprintf("Concert region for %s", artist.Name),
+				ArtistId:    artist.ID,
+			})
+		}
+
+		idx.add(fmt.Sprintf("%d", artist.CreationDate), models.SearchResult{
+			Text:        fmt.Sprintf("%s (%d)", artist.Name, artist.CreationDate),
+			Type:        TypeCreationDate,
+			ArtistName:  artist.Name,
+			Description: fmt.Sprintf("Band formed in %d", artist.CreationDate),
+			ArtistId:    artist.ID,
+		})
+
+		idx.add(artist.FirstAlbum, models.SearchResult{
+			Text:        fmt.Sprintf("%s - %s", artist.Name, artist.FirstAlbum),
+			Type:        TypeFirstAlbum,
+			ArtistName:  artist.Name,
+			Description: fmt.Sprintf("First album by %s", artist.Name),
+			ArtistId:    artist.ID,
+		})
+	}
+	return idx
+}
+
+// artistRegions returns the regions, countries and continents an artist
+// played in, except those that are also one of its concert locations.
+func artistRegions(artist models.Artist) []string {
+	seen := make(map[string]bool)
+	for _, location := range artist.LocationsList {
+		seen[location] = true
+	}
+
+	var regions []string
+	for _, location := range artist.LocationsList {
+		place, _ := utils.ResolvePlace(location)
+		for _, region := range []string{place.Region(), place.Country, place.Continent} {
+			if region != "" && !seen[region] {
+				seen[region] = true
+				regions = append(regions, region)
+			}
+		}
+	}
+	return regions
+}
+
+func (idx *SearchIndex) add(text string, result models.SearchResult) {
+	doc := len(idx.docs)
+	tokens := tokenize(text)
+	idx.docs = append(idx.docs, searchDoc{
+		result: result,
+		text:   normalize(text),
+		tokens: tokens,
+	})
+
+	trie := idx.tries[result.Type]
+	for _, token := range tokens {
+		trie.insert(token, doc)
+		idx.addVocab(token)
+	}
+}
+
+func (idx *SearchIndex) addVocab(token string) {
+	if _, exists := idx.vocabIDs[token]; exists {
+		return
+	}
+	id := len(idx.vocab)
+	idx.vocab = append(idx.vocab, token)
+	idx.vocabIDs[token] = id
+	for _, gram := range trigrams(token) {
+		idx.trigrams[gram] = append(idx.trigrams[gram], id)
+	}
+}
+
+// Search returns ranked matches for query. Query tokens match indexed tokens
+// exactly, by prefix, or within a small edit distance; a value must match at
+// least half of the query tokens. A limit of zero or less returns everything.
+func (idx *SearchIndex) Search(query string, limit int) []models.SearchResult {
+	return idx.search(query, limit, "", false)
+}
+
+// SearchField is like Search restricted to one result type, such as
+// TypeMember, and requires every query token to match.
+func (idx *SearchIndex) SearchField(field, query string) []models.SearchResult {
+	return idx.search(query, 0, field, true)
+}
+
+func (idx *SearchIndex) search(query string, limit int, only string, requireAll bool) []models.SearchResult {
+	queryTokens := tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+	normalizedQuery := normalize(strings.TrimSpace(query))
+	required := (len(queryTokens) + 1) / 2
+	if requireAll {
+		required = len(queryTokens)
+	}
+
+	fuzzy := make([]map[string]int, len(queryTokens))
+	for i, qt := range queryTokens {
+		fuzzy[i] = idx.fuzzyTerms(qt)
+	}
+
+	var results []models.SearchResult
+	for field, trie := range idx.tries {
+		if only != "" && field != only {
+			continue
+		}
+		totals := make(map[int]float64)
+		counts := make(map[int]int)
+		matched := make(map[int]map[string]int)
+		for i, qt := range queryTokens {
+			for doc, hit := range idx.tokenMatches(trie, qt, fuzzy[i]) {
+				totals[doc] += hit.score
+				counts[doc]++
+				if matched[doc] == nil {
+					matched[doc] = make(map[string]int)
+				}
+				matched[doc][hit.token] = max(matched[doc][hit.token], hit.length)
+			}
+		}
+
+		for doc, count := range counts {
+			if count < required {
+				continue
+			}
+			result := idx.docs[doc].result
+			result.Score = idx.score(doc, totals[doc], count, len(queryTokens), normalizedQuery) * fieldWeight[field]
+			result.Highlights = highlightSpans(result.Text, matched[doc])
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if typeOrder[results[i].Type] != typeOrder[results[j].Type] {
+			return typeOrder[results[i].Type] < typeOrder[results[j].Type]
+		}
+		if results[i].ArtistId != results[j].ArtistId {
+			return results[i].ArtistId < results[j].ArtistId
+		}
+		return results[i].Text < results[j].Text
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// tokenHit is the best match of one query token within a document: its
+// score, and how many leading runes of which indexed token it covered.
+type tokenHit struct {
+	score  float64
+	token  string
+	length int
+}
+
+// tokenMatches scores every document of one field against a single query
+// token: 2 for an exact token, up to 1 for a prefix by the share of the token
+// it covers, and up to 1.2 for a typo depending on the edit distance.
+func (idx *SearchIndex) tokenMatches(trie *trieNode, qt string, fuzzy map[string]int) map[int]tokenHit {
+	hits := make(map[int]tokenHit)
+	keep := func(doc int, hit tokenHit) {
+		if hit.score > hits[doc].score {
+			hits[doc] = hit
+		}
+	}
+
+	if node := trie.find(qt); node != nil {
+		qtLen := len([]rune(qt))
+		for _, doc := range node.prefix {
+			for _, token := range idx.docs[doc].tokens {
+				if token == qt {
+					keep(doc, tokenHit{score: 2, token: token, length: qtLen})
+				} else if strings.HasPrefix(token, qt) {
+					keep(doc, tokenHit{score: float64(len(qt)) / float64(len(token)), token: token, length: qtLen})
+				}
+			}
+		}
+	}
+
+	for term, distance := range fuzzy {
+		node := trie.find(term)
+		if node == nil {
+			continue
+		}
+		termLen := len([]rune(term))
+		score := 1.2 * (1 - float64(distance)/float64(termLen))
+		for _, doc := range node.exact {
+			keep(doc, tokenHit{score: score, token: term, length: termLen})
+		}
+	}
+	return hits
+}
+
+// score favours whole-value matches, then values starting with the query,
+// then the average token score, so partial matches rank below full ones.
+func (idx *SearchIndex) score(doc int, total float64, matched, queryTokens int, normalizedQuery string) float64 {
+	d := idx.docs[doc]
+	if matched == queryTokens {
+		switch {
+		case d.text == normalizedQuery:
+			return 10
+		case strings.HasPrefix(d.text, normalizedQuery):
+			return 6
+		}
+	}
+	return total / float64(queryTokens) * 2
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"groupie/models"
+)
+
+// snapshotVersion must be bumped whenever the snapshot layout or the way the
+// derived artist fields are computed changes, so stale files are ignored.
+const snapshotVersion = 3
+
+type snapshot struct {
+	Version int             `json:"version"`
+	SavedAt time.Time       `json:"savedAt"`
+	Artists []models.Artist `json:"artists"`
+}
+
+// SaveSnapshot writes the currently loaded artists to path. The file is
+// written to a temporary name first and renamed, so readers never see a
+// partial snapshot.
+func (ds *DataStore) SaveSnapshot(path string) error {
+	ds.mu.RLock()
+	data, err := json.Marshal(snapshot{
+		Version: snapshotVersion,
+		SavedAt: time.Now(),
+		Artists: ds.Artists,
+	})
+	ds.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create snapshot dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot replaces the loaded artists with the contents of a snapshot
+// previously written by SaveSnapshot and returns the time it was saved. The
+// load is recorded in the refresh status.
+func (ds *DataStore) LoadSnapshot(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return time.Time{}, fmt.Errorf("decode snapshot %s: %w", path, err)
+	}
+	if snap.Version != snapshotVersion {
+		return time.Time{}, fmt.Errorf("snapshot %s has version %d, want %d", path, snap.Version, snapshotVersion)
+	}
+	if len(snap.Artists) == 0 {
+		return time.Time{}, fmt.Errorf("snapshot %s contains no artists", path)
+	}
+
+	ds.setArtists(snap.Artists)
+
+	ds.mu.Lock()
+	ds.status.LastSuccess = snap.SavedAt
+	ds.status.Origin = "snapshot"
+	ds.status.Artists = len(snap.Artists)
+	ds.mu.Unlock()
+	return snap.SavedAt, nil
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"groupie/models"
+)
+
+const DefaultAPIURL = "https://groupietrackers.herokuapp.com/api"
+
+// Source provides the raw upstream dataset a DataStore is built from.
+// Relation may be called concurrently for different artists; every concert
+// is read from it.
+type Source interface {
+	Artists() ([]models.Artist, error)
+	Relation(artist models.Artist) (models.Relation, error)
+}
+
+// HTTPSource reads the dataset from the Groupie Trackers API or a mirror of it.
+type HTTPSource struct {
+	BaseURL string
+	client  *http.Client
+}
+
+func NewHTTPSource(baseURL string) *HTTPSource {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &HTTPSource{
+		BaseURL: baseURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *HTTPSource) fetchJSON(url string, target interface{}) error {
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("get %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: unexpected status %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
+}
+
+func (s *HTTPSource) Artists() ([]models.Artist, error) {
+	var index models.ApiIndex
+	if err := s.fetchJSON(s.BaseURL, &index); err != nil {
+		return nil, fmt.Errorf("failed to fetch API index: %w", err)
+	}
+
+	var artists []models.Artist
+	if err := s.fetchJSON(index.Artists, &artists); err != nil {
+		return nil, fmt.Errorf("failed to fetch artists: %w", err)
+	}
+	return artists, nil
+}
+
+func (s *HTTPSource) Relation(artist models.Artist) (models.Relation, error) {
+	var relation models.Relation
+	err := s.fetchJSON(artist.Relations, &relation)
+	return relation, err
+}
+
+// MemorySource serves a fixed dataset held in memory, keyed by artist ID.
+type MemorySource struct {
+	ArtistList  []models.Artist
+	RelationMap map[int]models.Relation
+}
+
+func (s *MemorySource) Artists() ([]models.Artist, error) {
+	artists := make([]models.Artist, len(s.ArtistList))
+	copy(artists, s.ArtistList)
+	return artists, nil
+}
+
+func (s *MemorySource) Relation(artist models.Artist) (models.Relation, error) {
+	relation, ok := s.RelationMap[artist.ID]
+	if !ok {
+		return models.Relation{}, fmt.Errorf("no relations for artist %d", artist.ID)
+	}
+	return relation, nil
+}
+
+// DirSource reads the dataset from a directory laid out like the upstream API:
+// artists.json and relation.json; locations.json and dates.json, which repeat
+// what relation.json holds, are not read. The files are
+// re-read on every call to Artists so a refresh picks up edits.
+type DirSource struct {
+	Dir string
+	mu  sync.RWMutex
+	mem MemorySource
+}
+
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{Dir: dir}
+}
+
+func (s *DirSource) readJSON(name string, target interface{}) error {
+	path := filepath.Join(s.Dir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+	return nil
+}
+
+func (s *DirSource) Artists() ([]models.Artist, error) {
+	var (
+		artists   []models.Artist
+		relations models.RelationIndex
+	)
+	if err := s.readJSON("artists.json", &artists); err != nil {
+		return nil, err
+	}
+	if err := s.readJSON("relation.json", &relations); err != nil {
+		return nil, err
+	}
+
+	mem := MemorySource{
+		ArtistList:  artists,
+		RelationMap: make(map[int]models.Relation, len(relations.Index)),
+	}
+	for _, relation := range relations.Index {
+		mem.RelationMap[relation.ID] = relation
+	}
+
+	s.mu.Lock()
+	s.mem = mem
+	s.mu.Unlock()
+
+	return mem.Artists()
+}
+
+func (s *DirSource) Relation(artist models.Artist) (models.Relation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mem.Relation(artist)
+}
@@ -1,11 +1,12 @@
 package store
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"log"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"groupie/models"
@@ -15,44 +16,60 @@ import (
 type DataStore struct {
 	Artists         []models.Artist
 	UniqueLocations []string
-	mu              sync.RWMutex
+	SnapshotPath    string
+
+	// CoordinateCachePath persists geocoding results across restarts; entries
+	// older than CoordinateTTL are geocoded again in the background.
+	CoordinateCachePath string
+	CoordinateTTL       time.Duration
+	Geocoder            Geocoder
+
+	searchIndex  *SearchIndex
+	concerts     []models.Concert
+	source       Source
+	mu           sync.RWMutex
+	coordLoading atomic.Bool
+	refreshMu    sync.Mutex
+	status       RefreshStatus
+	inflight     map[string]*coordinateCall
+	inflightMu   sync.Mutex
+	lookupQueue  struct {
+		ch     chan string
+		queued map[string]bool
+		mu     sync.Mutex
+		start  sync.Once
+	}
 	CoordinateCache struct {
-		data map[string]models.Coordinates
-		mu   sync.RWMutex
+		data     map[string]CoordinateEntry
+		failures map[string]GeocodeFailure
+		mu       sync.RWMutex
+		saveMu   sync.Mutex
 	}
 }
 
-func New() *DataStore {
-	return &DataStore{
-		Artists: make([]models.Artist, 0),
+func New(source Source) *DataStore {
+	ds := &DataStore{
+		Artists:  make([]models.Artist, 0),
+		source:   source,
+		Geocoder: NewRateLimitedGeocoder(NewNominatimGeocoder(""), NominatimInterval),
+		inflight: make(map[string]*coordinateCall),
 	}
+	ds.CoordinateCache.data = make(map[string]CoordinateEntry)
+	ds.CoordinateCache.failures = make(map[string]GeocodeFailure)
+	ds.lookupQueue.ch = make(chan string, 256)
+	ds.lookupQueue.queued = make(map[string]bool)
+	return ds
 }
 
+// Initialize performs the first full load from the source.
 func (ds *DataStore) Initialize() error {
-	client := &http.Client{Timeout: 10 * time.Second}
-	fetchJSON := func(url string, target interface{}) error {
-		resp, err := client.Get(url)
-		if err != nil {
-			return fmt.Errorf("get %s: %w", url, err)
-		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("get %s: unexpected status %s", url, resp.Status)
-		}
-		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
-			return fmt.Errorf("decode %s: %w", url, err)
-		}
-		return nil
-	}
-
-	var index models.ApiIndex
-	if err := fetchJSON("https://groupietrackers.herokuapp.com/api", &index); err != nil {
-		return fmt.Errorf("failed to fetch API index: %w", err)
-	}
+	return ds.Refresh()
+}
 
-	var artists []models.Artist
-	if err := fetchJSON(index.Artists, &artists); err != nil {
-		return fmt.Errorf("failed to fetch artists: %w", err)
+func (ds *DataStore) fetchArtists() ([]models.Artist, error) {
+	artists, err := ds.source.Artists()
+	if err != nil {
+		return nil, err
 	}
 
 	var wg sync.WaitGroup
@@ -62,41 +79,13 @@ func (ds *DataStore) Initialize() error {
 		wg.Add(1)
 		go func(artist *models.Artist) {
 			defer wg.Done()
-			artist.LocationStatesCities = make(map[string][]string)
-
-					var location models.Location
-			if err := fetchJSON(artist.Locations, &location); err != nil {
-				errChan <- fmt.Errorf("failed to fetch locations for artist %d: %w", artist.ID, err)
-				return
-			}
-
-			for _, loc := range location.Locations {
-				formattedLoc := utils.FormatLocation(loc)
-				artist.LocationsList = append(artist.LocationsList, formattedLoc)
-
-				for state, cities := range utils.StateCityMap {
-					for _, city := range cities {
-						if formattedLoc == city {
-							artist.LocationStatesCities[state] = append(artist.LocationStatesCities[state], city)
-						}
-					}
-				}
-			}
-			var date models.Date
-			if err := fetchJSON(artist.ConcertDates, &date); err != nil {
-				errChan <- fmt.Errorf("failed to fetch dates for artist %d: %w", artist.ID, err)
-				return
-			}
-			for _, date := range date.Dates {
-				artist.DatesList = append(artist.DatesList, utils.FormatDate(date))
-			}
-
-			var relation models.Relation
-			if err := fetchJSON(artist.Relations, &relation); err != nil {
+			relation, err := ds.source.Relation(*artist)
+			if err != nil {
 				errChan <- fmt.Errorf("failed to fetch relations for artist %d: %w", artist.ID, err)
 				return
 			}
-			artist.RelationsList = utils.FormatRelation(relation.DatesLocations)
+			artist.Concerts = buildArtistConcerts(*artist, relation)
+			describeConcerts(artist)
 		}(&artists[i])
 	}
 
@@ -107,13 +96,16 @@ func (ds *DataStore) Initialize() error {
 
 	for err := range errChan {
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	ds.mu.Lock()
-	ds.Artists = artists
+	return artists, nil
+}
 
+// setArtists builds the derived indexes for a fully loaded artist slice and
+// swaps them in together, so readers see either the old or the new dataset.
+func (ds *DataStore) setArtists(artists []models.Artist) {
 	locationMap := make(map[string]bool)
 	for _, artist := range artists {
 		for _, location := range artist.LocationsList {
@@ -121,15 +113,38 @@ func (ds *DataStore) Initialize() error {
 		}
 	}
 
-	ds.UniqueLocations = make([]string, 0, len(locationMap))
+	uniqueLocations := make([]string, 0, len(locationMap))
 	for location := range locationMap {
-		ds.UniqueLocations = append(ds.UniqueLocations, location)
+		uniqueLocations = append(uniqueLocations, location)
 	}
-	sort.Strings(ds.UniqueLocations)
+	sort.Strings(uniqueLocations)
+	reportUnplacedLocations(uniqueLocations)
+
+	searchIndex := NewSearchIndex(artists)
+	concerts := buildConcerts(artists)
+
+	ds.mu.Lock()
+	ds.Artists = artists
+	ds.UniqueLocations = uniqueLocations
+	ds.searchIndex = searchIndex
+	ds.concerts = concerts
 	ds.mu.Unlock()
+
 	ds.loadCoordinatesInBackground()
+}
 
-	return nil
+// reportUnplacedLocations logs locations whose country is missing from the
+// gazetteer, so they can be added; they match only by their full name.
+func reportUnplacedLocations(locations []string) {
+	var unplaced []string
+	for _, location := range locations {
+		if _, ok := utils.ResolvePlace(location); !ok {
+			unplaced = append(unplaced, location)
+		}
+	}
+	if len(unplaced) > 0 {
+		log.Printf("%d of %d locations are not in the gazetteer: %s", len(unplaced), len(locations), strings.Join(unplaced, "; "))
+	}
 }
 
 func (ds *DataStore) GetArtistCards() []models.ArtistCard {
@@ -153,12 +168,48 @@ func (ds *DataStore) GetArtist(id int) (models.Artist, error) {
 
 	for _, artist := range ds.Artists {
 		if artist.ID == id {
+			artist.Concerts = ds.locateConcerts(artist.Concerts)
+			artist.Tours = detectTours(artist.Concerts)
 			return artist, nil
 		}
 	}
 	return models.Artist{}, fmt.Errorf("artist with ID %d not found", id)
 }
 
+func (ds *DataStore) GetUniqueLocations() []string {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+
+	locations := make([]string, len(ds.UniqueLocations))
+	copy(locations, ds.UniqueLocations)
+	return locations
+}
+
+// Search queries the index built for the current dataset. A limit of zero or
+// less returns every match.
+func (ds *DataStore) Search(query string, limit int) []models.SearchResult {
+	ds.mu.RLock()
+	idx := ds.searchIndex
+	ds.mu.RUnlock()
+
+	if idx == nil {
+		return nil
+	}
+	return idx.Search(query, limit)
+}
+
+// SearchField searches a single result type, requiring every query token.
+func (ds *DataStore) SearchField(field, query string) []models.SearchResult {
+	ds.mu.RLock()
+	idx := ds.searchIndex
+	ds.mu.RUnlock()
+
+	if idx == nil {
+		return nil
+	}
+	return idx.SearchField(field, query)
+}
+
 func (ds *DataStore) GetAllArtists() []models.Artist {
 	ds.mu.RLock()
 	defer ds.mu.RUnlock()
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"math"
+	"time"
+
+	"groupie/models"
+	"groupie/utils"
+)
+
+const (
+	// tourBreak is the longest gap between two shows of the same tour.
+	tourBreak = 60 * 24 * time.Hour
+	// legBreak is the gap after which a long move, to another continent or
+	// further than tourLegKm, also starts a new tour.
+	legBreak  = 14 * 24 * time.Hour
+	tourLegKm = 3000
+)
+
+// detectTours groups an artist's date-ordered concerts into tours. A new
+// tour starts when the next show is more than tourBreak after the last one,
+// or more than legBreak after it and far away.
+func detectTours(concerts []models.Concert) []models.Tour {
+	var tours []models.Tour
+	for i, concert := range concerts {
+		if i == 0 || startsTour(concerts[i-1], concert) {
+			tours = append(tours, models.Tour{Start: concert.Date})
+		}
+		tour := &tours[len(tours)-1]
+		tour.Stops = append(tour.Stops, concert)
+		tour.End = concert.Date
+	}
+
+	for i := range tours {
+		tours[i].DistanceKm, tours[i].Complete = tourDistance(tours[i].Stops)
+	}
+	return tours
+}
+
+func startsTour(prev, next models.Concert) bool {
+	gap := next.Date.Sub(prev.Date)
+	if gap > tourBreak {
+		return true
+	}
+	return gap > legBreak && farApart(prev, next)
+}
+
+// farApart compares coordinates when both stops have them and continents
+// otherwise.
+func farApart(a, b models.Concert) bool {
+	if a.Coordinates != nil && b.Coordinates != nil {
+		return utils.Distance(*a.Coordinates, *b.Coordinates) > tourLegKm
+	}
+	return a.Continent != "" && b.Continent != "" && a.Continent != b.Continent
+}
+
+// tourDistance sums the legs between consecutive geocoded stops, in km to one
+// decimal. complete is false when some stop has no coordinates.
+func tourDistance(stops []models.Concert) (km float64, complete bool) {
+	complete = true
+	var last *models.Coordinates
+	for _, stop := range stops {
+		if stop.Coordinates == nil {
+			complete = false
+			continue
+		}
+		if last != nil {
+			km += utils.Distance(*last, *stop.Coordinates)
+		}
+		last = stop.Coordinates
+	}
+	return math.Round(km*10) / 10, complete
+}
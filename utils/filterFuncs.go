@@ -1,8 +1,6 @@
 package utils
 
 import (
-	"fmt"
-	"net/http"
 	"strconv"
 	"strings"
 
@@ -32,16 +30,6 @@ func ExtractYear(date string) int {
 	return year
 }
 
-func GetMemberCounts(r *http.Request) []int {
-	var counts []int
-	for i := 1; i <= 8; i++ {
-		if r.FormValue(fmt.Sprintf("members_%d", i)) != "" {
-			counts = append(counts, i)
-		}
-	}
-	return counts
-}
-
 func ConvertToCards(artists []models.Artist) []models.ArtistCard {
 	cards := make([]models.ArtistCard, len(artists))
 	for i, artist := range artists {
@@ -53,14 +41,3 @@ func ConvertToCards(artists []models.Artist) []models.ArtistCard {
 	}
 	return cards
 }
-
-func GetDefaultFilterParams() models.FilterParams {
-	return models.FilterParams{
-		CreationStart:  1950,
-		CreationEnd:    2024,
-		AlbumStartYear: 1950,
-		AlbumEndYear:   2024,
-		MemberCounts:   []int{},    // Empty slice - no members selected
-		Locations:      []string{}, // Empty slice - no locations selected
-	}
-}
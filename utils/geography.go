@@ -1,26 +1,171 @@
 package utils
 
-var StateCityMap = map[string][]string{
-	"Washington, Usa":     {"Seattle, Usa"},
-	"California, Usa":     {"Los Angeles, Usa", "Anaheim, Usa", "Oakland, Usa", "Del Mar, Usa", "San Francisco, Usa", "Pico Rivera, Usa", "Inglewood, Usa"},
-	"Missouri, Usa":       {"Kansas City, Usa", "St Louis, Usa"},
-	"Texas, Usa":          {"Dallas, Usa", "Houston, Usa"},
-	"Georgia, Usa":        {"Atlanta, Usa"},
-	"Massachusetts, Usa":  {"Boston, Usa"},
-	"New York, Usa":       {"Brooklyn, Usa", "Newark, Usa", "Uniondale, Usa"},
-	"Illinois, Usa":       {"Chicago, Usa", "Berwyn, Usa", "Rosemont, Usa"},
-	"Pennsylvania, Usa":   {"Philadelphia, Usa", "Pittsburgh, Usa", "Hershey, Usa"},
-	"Michigan, Usa":       {"Grand Rapids, Usa", "Detroit, Usa"},
-	"Indiana, Usa":        {"Indianapolis, Usa"},
-	"Ohio, Usa":           {"Cleveland, Usa", "Cincinnati, Usa"},
-	"Nebraska, Usa":       {"Omaha, Usa"},
-	"North Carolina, Usa": {"Charlotte, Usa", "Columbia, Usa"},
-	"Louisiana, Usa":      {"New Orleans, Usa"},
-	"Wisconsin, Usa":      {"Madison, Usa"},
-	"Nevada, Usa":         {"Las Vegas, Usa"},
-	// Australian states and cities
-	"Victoria, Australia":          {"Melbourne, Australia", "West Melbourne, Australia"},
-	"New South Wales, Australia":   {"Sydney, Australia"},
-	"Queensland, Australia":        {"Brisbane, Australia"},
-	"Western Australia, Australia": {"Burswood, Australia"},
+import (
+	"math"
+	"strings"
+
+	"groupie/models"
+)
+
+// continents maps every country, written as FormatLocation writes it, to its
+// continent.
+var continents = map[string]string{
+	// North America
+	"Usa": "North America", "Canada": "North America", "Mexico": "North America",
+	"Costa Rica": "North America", "Puerto Rico": "North America", "Guatemala": "North America",
+	"Panama": "North America", "Netherlands Antilles": "North America",
+
+	// South America
+	"Argentina": "South America", "Brazil": "South America", "Chile": "South America",
+	"Colombia": "South America", "Peru": "South America", "Uruguay": "South America",
+	"Paraguay": "South America", "Ecuador": "South America", "Venezuela": "South America",
+	"Bolivia": "South America",
+
+	// Europe
+	"Uk": "Europe", "Ireland": "Europe", "France": "Europe", "Germany": "Europe",
+	"Netherlands": "Europe", "Belgium": "Europe", "Luxembourg": "Europe", "Switzerland": "Europe",
+	"Austria": "Europe", "Italy": "Europe", "Spain": "Europe", "Portugal": "Europe",
+	"Denmark": "Europe", "Norway": "Europe", "Sweden": "Europe", "Finland": "Europe",
+	"Iceland": "Europe", "Poland": "Europe", "Czechia": "Europe", "Czech Republic": "Europe",
+	"Slovakia": "Europe", "Hungary": "Europe", "Slovenia": "Europe", "Croatia": "Europe",
+	"Serbia": "Europe", "Romania": "Europe", "Bulgaria": "Europe", "Greece": "Europe",
+	"Ukraine": "Europe", "Belarus": "Europe", "Russia": "Europe", "Lithuania": "Europe",
+	"Latvia": "Europe", "Estonia": "Europe", "Turkey": "Europe",
+
+	// Asia
+	"Japan": "Asia", "China": "Asia", "South Korea": "Asia", "Taiwan": "Asia",
+	"Hong Kong": "Asia", "Singapore": "Asia", "Thailand": "Asia", "Philippines": "Asia",
+	"Indonesia": "Asia", "Malaysia": "Asia", "Vietnam": "Asia", "India": "Asia",
+	"United Arab Emirates": "Asia", "Qatar": "Asia", "Saudi Arabia": "Asia",
+	"Israel": "Asia", "Lebanon": "Asia", "Kazakhstan": "Asia",
+
+	// Africa
+	"South Africa": "Africa", "Egypt": "Africa", "Morocco": "Africa", "Nigeria": "Africa",
+	"Kenya": "Africa",
+
+	// Oceania
+	"Australia": "Oceania", "New Zealand": "Oceania", "New Caledonia": "Oceania",
+	"French Polynesia": "Oceania", "Fiji": "Oceania",
+}
+
+// regions lists, per country, the states or regions the gazetteer knows and
+// the cities within them.
+var regions = map[string]map[string][]string{
+	"Usa": {
+		"Washington":     {"Seattle"},
+		"California":     {"Los Angeles", "Anaheim", "Oakland", "Del Mar", "San Francisco", "Pico Rivera", "Inglewood"},
+		"Missouri":       {"Kansas City", "St Louis"},
+		"Texas":          {"Dallas", "Houston"},
+		"Georgia":        {"Atlanta"},
+		"Massachusetts":  {"Boston"},
+		"New York":       {"New York", "Brooklyn", "Uniondale"},
+		"New Jersey":     {"Newark"},
+		"Illinois":       {"Chicago", "Berwyn", "Rosemont"},
+		"Pennsylvania":   {"Philadelphia", "Pittsburgh", "Hershey"},
+		"Michigan":       {"Grand Rapids", "Detroit"},
+		"Indiana":        {"Indianapolis"},
+		"Ohio":           {"Cleveland", "Cincinnati"},
+		"Nebraska":       {"Omaha"},
+		"North Carolina": {"Charlotte"},
+		"South Carolina": {"Columbia"},
+		"Louisiana":      {"New Orleans"},
+		"Wisconsin":      {"Madison"},
+		"Nevada":         {"Las Vegas"},
+	},
+	"Canada": {
+		"Quebec":           {"Montreal"},
+		"Ontario":          {"Toronto"},
+		"British Columbia": {"Vancouver"},
+	},
+	"Mexico": {
+		"Mexico City":  {"Mexico City"},
+		"Jalisco":      {"Guadalajara"},
+		"Nuevo Leon":   {"Monterrey"},
+		"Quintana Roo": {"Playa Del Carmen"},
+	},
+	"Argentina": {
+		"Buenos Aires": {"Buenos Aires", "La Plata", "San Isidro"},
+	},
+	"Uk": {
+		"England":  {"London", "Birmingham", "Manchester"},
+		"Scotland": {"Glasgow"},
+	},
+	"Germany": {
+		"Berlin":                 {"Berlin"},
+		"Hamburg":                {"Hamburg"},
+		"Bavaria":                {"Munich"},
+		"Hesse":                  {"Frankfurt"},
+		"North Rhine-Westphalia": {"Dusseldorf"},
+	},
+	"Spain": {
+		"Catalonia": {"Barcelona"},
+		"Madrid":    {"Madrid"},
+		"Aragon":    {"Zaragoza"},
+	},
+	"Japan": {
+		"Tokyo":   {"Tokyo"},
+		"Osaka":   {"Osaka"},
+		"Aichi":   {"Nagoya"},
+		"Saitama": {"Saitama"},
+	},
+	"Australia": {
+		"Victoria":          {"Melbourne", "West Melbourne"},
+		"New South Wales":   {"Sydney"},
+		"Queensland":        {"Brisbane"},
+		"Western Australia": {"Burswood"},
+	},
+	"New Zealand": {
+		"Auckland":   {"Auckland", "Penrose"},
+		"Otago":      {"Dunedin"},
+		"Wellington": {"Wellington"},
+	},
+}
+
+// cityRegions maps "City, Country" to the city's region, built from regions.
+var cityRegions = func() map[string]string {
+	index := make(map[string]string)
+	for country, states := range regions {
+		for state, cities := range states {
+			for _, city := range cities {
+				index[city+", "+country] = state
+			}
+		}
+	}
+	return index
+}()
+
+// ResolvePlace places a formatted location such as "Seattle, Usa" in the
+// hierarchy. A location naming a region, such as "California, Usa", has no
+// city. ok is false when the country is missing from the gazetteer.
+func ResolvePlace(location string) (place models.Place, ok bool) {
+	place.Location = location
+	i := strings.LastIndex(location, ", ")
+	if i < 0 {
+		place.City = location
+		return place, false
+	}
+	name, country := location[:i], location[i+2:]
+	place.Country = country
+	place.Continent, ok = continents[country]
+
+	if state, found := cityRegions[location]; found {
+		place.City, place.State = name, state
+	} else if _, found := regions[country][name]; found {
+		place.State = name
+	} else {
+		place.City = name
+	}
+	return place, ok
+}
+
+const earthRadiusKm = 6371
+
+// Distance returns the great-circle distance between two points in km.
+func Distance(a, b models.Coordinates) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
 }
@@ -29,36 +29,10 @@ func FormatLocation(location string) string {
 	return parts[0]
 }
 
-func FormatDate(date string) string {
-	if strings.HasPrefix(date, "*") {
-		date = strings.TrimSpace(date[1:])
-	}
-	t, err := time.Parse("02-01-2006", date)
-	if err != nil {
-		return date // Return original if parsing fails
-	}
-
-	// Format in a more readable way
-	return t.Format("January 2, 2006")
-}
-
-func FormatRelation(relations map[string][]string) map[string][]string {
-	formatted := make(map[string][]string)
-
-	for loc, dates := range relations {
-		// Format the location
-		formattedLoc := FormatLocation(loc)
-
-		// Format each date
-		formattedDates := make([]string, len(dates))
-		for i, date := range dates {
-			formattedDates[i] = FormatDate(date)
-		}
-
-		formatted[formattedLoc] = formattedDates
-	}
-
-	return formatted
+// ParseDate parses an upstream "DD-MM-YYYY" date. A leading "*", which the
+// upstream API uses to mark some dates, is ignored.
+func ParseDate(date string) (time.Time, error) {
+	return time.Parse("02-01-2006", strings.TrimSpace(strings.TrimPrefix(date, "*")))
 }
 
 func FormatLocationsList(locations []string) []string {
@@ -68,3 +42,21 @@ func FormatLocationsList(locations []string) []string {
 	}
 	return formatted
 }
+
+// PageBounds returns the slice bounds of a 1-based page of size limit over
+// total items. Pages past the end yield an empty range; they are checked
+// before multiplying so a huge page cannot overflow.
+func PageBounds(total, page, limit int) (start, end int) {
+	if page-1 >= TotalPages(total, limit) {
+		return total, total
+	}
+	start = (page - 1) * limit
+	return start, min(start+limit, total)
+}
+
+func TotalPages(total, limit int) int {
+	if total == 0 {
+		return 0
+	}
+	return (total + limit - 1) / limit
+}